@@ -0,0 +1,542 @@
+// Package bijoy detects text in the Bijoy (SutonnyMJ) legacy encoding and
+// converts it to Unicode Bangla. Bijoy fonts draw Bangla glyphs over ASCII and
+// Windows-1252 code points and store text in visual order: the vowel signs ি,
+// ে and ৈ come before their consonant, and reph (©) after it. The converter
+// maps the glyphs and then moves those signs to their Unicode position.
+package bijoy
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+)
+
+// Encodings returned by Detect
+const (
+	Unicode = "unicode"
+	Bijoy   = "bijoy"
+	Unknown = "unknown"
+)
+
+const (
+	hasanta = '\u09CD'
+	nukta   = '\u09BC'
+	zwnj    = '\u200C'
+	zwj     = '\u200D'
+	// reph marks a Bijoy reph until it is moved before its cluster
+	reph = '\uE000'
+)
+
+// glyphs maps Bijoy characters to Unicode in visual order. Longer keys win.
+var glyphs = []struct {
+	from, to string
+}{
+	// Conjunct glyphs and combinations, listed before their parts
+	{"i¨", "\u09B0\u200D\u09CD\u09AF"},
+	{"ª¨", "\u09CD\u09B0\u09CD\u09AF"},
+	{"°", "ক্ক"},
+	{"±", "ক্ট"},
+	{"³", "ক্ত"},
+	{"K¡", "ক্ব"},
+	{"¯Œ", "স্ক্র"},
+	{"µ", "ক্র"},
+	{"K¬", "ক্ল"},
+	{"¶", "ক্ষ"},
+	{"ÿ", "ক্ষ"},
+	{"·", "ক্স"},
+	{"¸", "গু"},
+	{"»", "গ্ধ"},
+	{"Mœ", "গ্ন"},
+	{"M¥", "গ্ম"},
+	{"Mø", "গ্ল"},
+	{"¼", "ঙ্ক"},
+	{"•¶", "ঙ্ক্ষ"},
+	{"•L", "ঙ্খ"},
+	{"½", "ঙ্গ"},
+	{"•N", "ঙ্ঘ"},
+	{"”P", "চ্চ"},
+	{"”Q", "চ্ছ"},
+	{"”Q¡", "চ্ছ্ব"},
+	{"”T", "চ্ঞ"},
+	{"¾¡", "জ্জ্ব"},
+	{"¾", "জ্জ"},
+	{"À", "জ্ঝ"},
+	{"Á", "জ্ঞ"},
+	{"R¡", "জ্ব"},
+	{"Â", "ঞ্চ"},
+	{"Ã", "ঞ্ছ"},
+	{"Ä", "ঞ্জ"},
+	{"Å", "ঞ্ঝ"},
+	{"Æ", "ট্ট"},
+	{"U¡", "ট্ব"},
+	{"U¥", "ট্ম"},
+	{"Ç", "ড্ড"},
+	{"È", "ণ্ট"},
+	{"É", "ণ্ঠ"},
+	{"Ê", "ণ্ড"},
+	{"Y^", "ণ্ব"},
+	{"Ë¡", "ত্ত্ব"},
+	{"Ë", "ত্ত"},
+	{"Ì", "ত্থ"},
+	{"Zœ", "ত্ন"},
+	{"Z¥", "ত্ম"},
+	{"Z¡", "ত্ব"},
+	{"Î", "ত্র"},
+	{"_¡", "থ্ব"},
+	{"˜M", "দ্গ"},
+	{"˜N", "দ্ঘ"},
+	{"Ï", "দ্দ"},
+	{"×", "দ্ধ"},
+	{"˜¡", "দ্ব"},
+	{"Ø", "দ্ব"},
+	{"™¢", "দ্ভ"},
+	{"Ù", "দ্ম"},
+	{"`ª“", "দ্রু"},
+	{"aŸ", "ধ্ব"},
+	{"a¥", "ধ্ম"},
+	{"›U", "ন্ট"},
+	{"Ú", "ন্ঠ"},
+	{"Û", "ন্ড"},
+	{"š¿", "ন্ত্র"},
+	{"š—", "ন্ত"},
+	{"š’", "ন্থ"},
+	{"›`", "ন্দ"},
+	{"›Ø", "ন্দ্ব"},
+	{"Ü", "ন্ধ"},
+	{"bœ", "ন্ন"},
+	{"š^", "ন্ব"},
+	{"b¥", "ন্ম"},
+	{"Ý", "ন্স"},
+	{"Þ", "প্ট"},
+	{"ß", "প্ত"},
+	{"cœ", "প্ন"},
+	{"à", "প্প"},
+	{"cø", "প্ল"},
+	{"á", "প্স"},
+	{"d¬", "ফ্ল"},
+	{"â", "ব্জ"},
+	{"ã", "ব্দ"},
+	{"ä", "ব্ধ"},
+	{"eŸ", "ব্ব"},
+	{"eø", "ব্ল"},
+	{"å", "ভ্র"},
+	{"gœ", "ম্ন"},
+	{"¤ú", "ম্প"},
+	{"ç", "ম্ফ"},
+	{"¤^", "ম্ব"},
+	{"¤¢", "ম্ভ"},
+	{"¤£", "ম্ভ্র"},
+	{"¤§", "ম্ম"},
+	{"¤ø", "ম্ল"},
+	{"iæ", "রু"},
+	{"iƒ", "রূ"},
+	{"é", "ল্ক"},
+	{"ê", "ল্গ"},
+	{"ë", "ল্ট"},
+	{"ì", "ল্ড"},
+	{"í", "ল্প"},
+	{"î", "ল্ফ"},
+	{"j¦", "ল্ব"},
+	{"j¥", "ল্ম"},
+	{"jø", "ল্ল"},
+	{"ï", "শু"},
+	{"ð", "শ্চ"},
+	{"kœ", "শ্ন"},
+	{"k¦", "শ্ব"},
+	{"k¥", "শ্ম"},
+	{"kø", "শ্ল"},
+	{"®‹", "ষ্ক"},
+	{"®Œ", "ষ্ক্র"},
+	{"ó", "ষ্ট"},
+	{"ô", "ষ্ঠ"},
+	{"ò", "ষ্ণ"},
+	{"®ú", "ষ্প"},
+	{"õ", "ষ্ফ"},
+	{"®§", "ষ্ম"},
+	{"¯‹", "স্ক"},
+	{"÷", "স্ট"},
+	{"ö", "স্খ"},
+	{"¯Í", "স্ত"},
+	{"¯’", "স্থ"},
+	{"¯œ", "স্ন"},
+	{"¯ú", "স্প"},
+	{"ù", "স্ফ"},
+	{"¯^", "স্ব"},
+	{"¯§", "স্ম"},
+	{"¯ø", "স্ল"},
+	{"û", "হু"},
+	{"nè", "হ্ণ"},
+	{"nŸ", "হ্ব"},
+	{"ý", "হ্ন"},
+	{"þ", "হ্ম"},
+	{"n¬", "হ্ল"},
+	{"ü", "হৃ"},
+
+	// Vowels
+	{"Av", "আ"},
+	{"A", "অ"},
+	{"B", "ই"},
+	{"C", "ঈ"},
+	{"D", "উ"},
+	{"E", "ঊ"},
+	{"F", "ঋ"},
+	{"G", "এ"},
+	{"H", "ঐ"},
+	{"I", "ও"},
+	{"J", "ঔ"},
+
+	// Consonants
+	{"K", "ক"},
+	{"L", "খ"},
+	{"M", "গ"},
+	{"N", "ঘ"},
+	{"O", "ঙ"},
+	{"P", "চ"},
+	{"Q", "ছ"},
+	{"R", "জ"},
+	{"S", "ঝ"},
+	{"T", "ঞ"},
+	{"U", "ট"},
+	{"V", "ঠ"},
+	{"W", "ড"},
+	{"X", "ঢ"},
+	{"Y", "ণ"},
+	{"Z", "ত"},
+	{"_", "থ"},
+	{"`", "দ"},
+	{"a", "ধ"},
+	{"b", "ন"},
+	{"c", "প"},
+	{"d", "ফ"},
+	{"e", "ব"},
+	{"f", "ভ"},
+	{"g", "ম"},
+	{"h", "য"},
+	{"i", "র"},
+	{"j", "ল"},
+	{"k", "শ"},
+	{"l", "ষ"},
+	{"m", "স"},
+	{"n", "হ"},
+	{"o", "\u09DC"},
+	{"p", "\u09DD"},
+	{"q", "\u09DF"},
+	{"r", "ৎ"},
+
+	// Signs; "z", "“" and "–" are alternative glyphs of the u-kar
+	{"s", "\u0982"},
+	{"t", "\u0983"},
+	{"u", "\u0981"},
+	{"v", "\u09BE"},
+	{"w", "\u09BF"},
+	{"x", "\u09C0"},
+	{"y", "\u09C1"},
+	{"z", "\u09C1"},
+	{"“", "\u09C1"},
+	{"–", "\u09C1"},
+	{"~", "\u09C2"},
+	{"ƒ", "\u09C2"},
+	{"‚", "\u09C2"},
+	{"„„", "\u09C3"},
+	{"„", "\u09C3"},
+	{"…", "\u09C3"},
+	{"‡", "\u09C7"},
+	{"†", "\u09C7"},
+	{"‰", "\u09C8"},
+	{"ˆ", "\u09C8"},
+	{"Š", "\u09D7"},
+	{"&", "\u09CD"},
+	{"|", "।"},
+
+	// Half forms end in a hasanta, subscript forms start with one
+	{"”", "চ্"},
+	{"•", "ঙ্"},
+	{"˜", "দ্"},
+	{"™", "দ্"},
+	{"š", "ন্"},
+	{"›", "ন্"},
+	{"¤", "ম্"},
+	{"®", "ষ্"},
+	{"¯", "স্"},
+	{"^", "\u09CD\u09AC"},
+	{"‘", "\u09CD\u09A4\u09C1"},
+	{"’", "\u09CD\u09A5"},
+	{"‹", "\u09CD\u0995"},
+	{"Œ", "\u09CD\u0995\u09CD\u09B0"},
+	{"—", "\u09CD\u09A4"},
+	{"Í", "\u09CD\u09A4"},
+	{"œ", "\u09CD\u09A8"},
+	{"¥", "\u09CD\u09AE"},
+	{"§", "\u09CD\u09AE"},
+	{"¦", "\u09CD\u09AC"},
+	{"Ÿ", "\u09CD\u09AC"},
+	{"¡", "\u09CD\u09AC"},
+	{"¢", "\u09CD\u09AD"},
+	{"£", "\u09CD\u09AD\u09CD\u09B0"},
+	{"¨", "\u09CD\u09AF"},
+	{"ª", "\u09CD\u09B0"},
+	{"«", "\u09CD\u09B0"},
+	{"Ö", "\u09CD\u09B0"},
+	{"¬", "\u09CD\u09B2"},
+	{"ø", "\u09CD\u09B2"},
+	{"ú", "\u09CD\u09AA"},
+	{"¿", "\u09CD\u09A4\u09CD\u09B0"},
+	{"æ", "\u09C1"},
+	{"è", "\u09CD\u09A3"},
+
+	// Quotes
+	{"Ô", "‘"},
+	{"Õ", "’"},
+	{"Ò", "“"},
+	{"Ó", "”"},
+
+	// Digits
+	{"0", "০"},
+	{"1", "১"},
+	{"2", "২"},
+	{"3", "৩"},
+	{"4", "৪"},
+	{"5", "৫"},
+	{"6", "৬"},
+	{"7", "৭"},
+	{"8", "৮"},
+	{"9", "৯"},
+}
+
+var (
+	glyphMap    = make(map[string]string, len(glyphs))
+	maxGlyphLen int               // In runes
+	markers     = map[rune]bool{} // Non-ASCII characters used by Bijoy glyphs
+)
+
+func init() {
+	for _, g := range glyphs {
+		glyphMap[g.from] = g.to
+		if n := utf8.RuneCountInString(g.from); n > maxGlyphLen {
+			maxGlyphLen = n
+		}
+		for _, r := range g.from {
+			if r > unicode.MaxASCII {
+				markers[r] = true
+			}
+		}
+	}
+	markers['©'] = true
+}
+
+// Windows-1252 characters in 0x80-0x9F; the other bytes are Latin-1
+var windows1252 = [32]rune{
+	'€', utf8.RuneError, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', utf8.RuneError, 'Ž', utf8.RuneError,
+	utf8.RuneError, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', utf8.RuneError, 'ž', 'Ÿ',
+}
+
+// Decode returns b as a string. Files saved from Bijoy editors are usually
+// Windows-1252 rather than UTF-8, so invalid UTF-8 is decoded as Windows-1252.
+func Decode(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	var sb strings.Builder
+	for _, c := range b {
+		if c >= 0x80 && c < 0xA0 {
+			sb.WriteRune(windows1252[c-0x80])
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// punctuation are Bijoy glyph characters that English text uses as
+// punctuation. They never count as Bijoy evidence, except reph (©), the
+// u-kar “ and ক্ক (°) right after a letter, where English does not put them.
+var punctuation = map[rune]bool{
+	'‘': true, '’': true, '“': true, '”': true, '–': true, '—': true,
+	'…': true, '•': true, '™': true, '©': true, '®': true, '°': true,
+}
+
+// Detect guesses the encoding of a text: Unicode if most letters are in the
+// Bengali script, Bijoy if at least a fifth of the words look like Bijoy, and
+// Unknown otherwise (e.g. English). A word is clearly Bijoy if it holds a
+// glyph character that is neither punctuation nor a Latin letter, like "‡`k"
+// (দেশ) or "Kg©" (কর্ম). Capitals after the first letter, like "MvB" (গাই),
+// and accented letters also occur in English and European words ("iPhone",
+// "NASA", "café"), so they only count on a line with a clearly Bijoy word.
+func Detect(text string) string {
+	bengali, letters := 0, 0
+	words, clearWords, bijoyWords := 0, 0, 0
+	for _, word := range strings.Fields(text) {
+		words++
+		clear, weak := false, false
+		prev := rune(0)
+		for i, r := range word {
+			switch {
+			case unicode.Is(unicode.Bengali, r):
+				bengali++
+			case markers[r] && punctuation[r]:
+				if unicode.IsLetter(prev) && (r == '©' || r == '“' || r == '°') {
+					clear = true
+				}
+			case markers[r] && unicode.Is(unicode.Latin, r):
+				weak = true
+			case markers[r]:
+				clear = true
+			case r < utf8.RuneSelf && unicode.IsUpper(r) && i > 0:
+				weak = true
+			}
+			if unicode.IsLetter(r) || markers[r] {
+				letters++
+			}
+			prev = r
+		}
+		if clear {
+			clearWords++
+		}
+		if clear || weak {
+			bijoyWords++
+		}
+	}
+	switch {
+	case bengali > 0 && bengali*2 >= letters:
+		return Unicode
+	case clearWords > 0 && bijoyWords*5 >= words:
+		return Bijoy
+	}
+	return Unknown
+}
+
+// Convert converts Bijoy text to canonical Unicode Bangla
+func Convert(text string) string {
+	runes := mapGlyphs([]rune(text))
+	runes = reorderVowelSigns(runes)
+	runes = moveReph(runes)
+	// Canonicalize composes ে + া and ে + ৗ, which now follow the consonant
+	return normalize.Canonicalize(string(runes), nil)
+}
+
+// mapGlyphs replaces Bijoy characters with Unicode, longest match first. A
+// half form followed by a subscript form gives one hasanta, not two.
+func mapGlyphs(in []rune) []rune {
+	out := make([]rune, 0, len(in))
+	for i := 0; i < len(in); {
+		if in[i] == '©' {
+			out = append(out, reph)
+			i++
+			continue
+		}
+		n := maxGlyphLen
+		if len(in)-i < n {
+			n = len(in) - i
+		}
+		for ; n > 0; n-- {
+			if to, ok := glyphMap[string(in[i:i+n])]; ok {
+				for _, r := range to {
+					if r == hasanta && len(out) > 0 && out[len(out)-1] == hasanta {
+						continue
+					}
+					out = append(out, r)
+				}
+				break
+			}
+		}
+		if n == 0 {
+			out = append(out, in[i])
+			n = 1
+		}
+		i += n
+	}
+	return out
+}
+
+// reorderVowelSigns moves ি, ে and ৈ from before a consonant cluster to
+// after it
+func reorderVowelSigns(rs []rune) []rune {
+	out := make([]rune, 0, len(rs))
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		if (r == '\u09BF' || r == '\u09C7' || r == '\u09C8') && i+1 < len(rs) && isConsonant(rs[i+1]) {
+			end := clusterEnd(rs, i+1)
+			out = append(out, rs[i+1:end]...)
+			out = append(out, r)
+			i = end - 1
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// moveReph moves each reph before the consonant cluster and vowel signs it
+// follows, as র + hasanta
+func moveReph(rs []rune) []rune {
+	out := make([]rune, 0, len(rs)+len(rs)/8)
+	for _, r := range rs {
+		if r != reph {
+			out = append(out, r)
+			continue
+		}
+		k := len(out)
+		for k > 0 && isSign(out[k-1]) {
+			k--
+		}
+		if k > 0 && isConsonant(out[k-1]) {
+			k--
+			for k >= 2 && out[k-1] == hasanta && isConsonant(out[k-2]) {
+				k -= 2
+			}
+		} else {
+			k = len(out) // Nothing to attach to, keep it in place
+		}
+		out = append(out[:k], append([]rune{'র', hasanta}, out[k:]...)...)
+	}
+	return out
+}
+
+// clusterEnd returns the index after the consonant cluster starting at start
+func clusterEnd(rs []rune, start int) int {
+	k := start + 1
+	for k < len(rs) {
+		if rs[k] == nukta {
+			k++
+			continue
+		}
+		j := k
+		if rs[j] == zwj || rs[j] == zwnj {
+			j++
+		}
+		if j+1 < len(rs) && rs[j] == hasanta && isConsonant(rs[j+1]) {
+			k = j + 2
+			continue
+		}
+		break
+	}
+	return k
+}
+
+func isConsonant(r rune) bool {
+	return (r >= '\u0995' && r <= '\u09B9') || r == '\u09DC' || r == '\u09DD' || r == '\u09DF' || r == '\u09F0' || r == '\u09F1'
+}
+
+// isSign reports whether r is a vowel sign or nukta, which a reph skips over
+func isSign(r rune) bool {
+	return (r >= '\u09BE' && r <= '\u09CC') || r == '\u09D7' || r == nukta
+}
+
+// Converter converts the lines of a file, or in Auto mode only the lines
+// Detect takes for Bijoy, and counts them
+type Converter struct {
+	Auto      bool
+	Converted int
+}
+
+// Apply returns the line in Unicode
+func (c *Converter) Apply(line string) string {
+	line = Decode([]byte(line))
+	if c.Auto && Detect(line) != Bijoy {
+		return line
+	}
+	c.Converted++
+	return Convert(line)
+}
@@ -0,0 +1,66 @@
+package bijoy
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"আমার দেশ বাংলাদেশ", Unicode},
+		{"বাংলাদেশ Bangladesh", Unicode},
+		{"Avgvi ‡`k evsjv‡`k", Bijoy},
+		{"Avwg Kg© Kwi", Bijoy},
+		{"iex›`ªbv_ VvKzi GKRb Kwe", Bijoy},
+		{"gvbyl wkí fv‡jvevÕm", Bijoy},
+		// Without a clearly Bijoy glyph a line is left alone
+		{"Avwg evsjvq Mvb MvB", Unknown},
+		// English, with acronyms, camel case, typography and accents
+		{"The NASA and FBI report", Unknown},
+		{"iPhone sales rose in USA", Unknown},
+		{"I love BD", Unknown},
+		{"It’s a “well–known” fact… © 2024 Acme™", Unknown},
+		{"Visit the café in Zürich", Unknown},
+		{"McDonald’s opened in 1990–2000", Unknown},
+		{"", Unknown},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.text); got != tt.want {
+			t.Errorf("Detect(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		bijoy, want string
+	}{
+		{"Avwg evsjvq Mvb MvB", "আমি বাংলা\u09DF গান গাই"},
+		{"Avgvi ‡`k evsjv‡`k", "আমার দেশ বাংলাদেশ"},
+		{"Kg© I ag©", "কর্ম ও ধর্ম"},
+		{"iex›`ªbv_ VvKzi", "রবীন্দ্রনাথ ঠাকুর"},
+		{"‡jvK", "লোক"},
+		{"‰e‡`wkK", "বৈদেশিক"},
+		{"wkí", "শিল্প"},
+		{"1971 mv‡j|", "১৯৭১ সালে।"},
+	}
+	for _, tt := range tests {
+		if got := Convert(tt.bijoy); got != tt.want {
+			t.Errorf("Convert(%q) = %q, want %q", tt.bijoy, got, tt.want)
+		}
+	}
+}
+
+func TestConverterAutoKeepsEnglish(t *testing.T) {
+	c := &Converter{Auto: true}
+	for _, line := range []string{"The NASA and FBI report", "iPhone sales rose in USA", "I love BD", "আমার দেশ"} {
+		if got := c.Apply(line); got != line {
+			t.Errorf("Apply(%q) = %q, want it unchanged", line, got)
+		}
+	}
+	if got := c.Apply("Avgvi ‡`k"); got != "আমার দেশ" {
+		t.Errorf("Apply converted to %q, want %q", got, "আমার দেশ")
+	}
+	if c.Converted != 1 {
+		t.Errorf("Converted = %d, want 1", c.Converted)
+	}
+}
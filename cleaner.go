@@ -1,38 +1,243 @@
 package main
 
 import (
+	"bufio"
+	"flag"
 	"fmt"
-	"regexp"
+	"io"
+	"os"
 	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/bijoy"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/numerals"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/scrub"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/translit"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/validate"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/variant"
 )
 
-// removeNukta replaces nuktas in Bangla text with their corresponding replacements
-func removeNukta(banglaText string) string {
-	nuktaReplacements := map[string]string{
-		"\u09A1\u09BC": "\u09DC", // \u09DC is 2524 in decimal
-		"\u09A2\u09BC": "\u09DD", // \u09DD is 2525 in decimal
-		"\u09AF\u09BC": "\u09DF", // \u09DF is 2527 in decimal
-	}
+// preprocessText cleans normalized text. cleanLine normalizes each line once,
+// counting the rewrites in audit mode.
+func preprocessText(input string, lang *profile.Profile, numbers *numerals.Policy) string {
+	// Split into words, keeping conjuncts and compounds intact, and apply
+	// the numeral policy
+	words := numbers.Words(input)
+	return strings.Join(words, " ")
+
+}
 
-	for nuktaChar, replacement := range nuktaReplacements {
-		banglaText = strings.ReplaceAll(banglaText, nuktaChar, replacement)
+// cleanText cleans a text, optionally writing one sentence per line. Sentences
+// are found before preprocessText, since it drops the danda.
+func cleanText(input string, opts CleanOptions) string {
+	if !opts.SentencePerLine {
+		return preprocessText(input, opts.Profile, opts.Numbers)
+	}
+	var sentences []string
+	for _, sentence := range opts.Profile.Sentences(input) {
+		if cleaned := preprocessText(sentence, opts.Profile, opts.Numbers); cleaned != "" {
+			sentences = append(sentences, cleaned)
+		}
 	}
+	return strings.Join(sentences, "\n")
+}
 
-	return banglaText
+// CleanOptions controls the optional cleaning stages
+type CleanOptions struct {
+	Profile         *profile.Profile
+	Numbers         *numerals.Policy
+	SentencePerLine bool
+	Audit           normalize.Audit  // Counts canonicalization rewrites when not nil
+	Validate        string           // "flag" or "repair" malformed text, or "" to skip
+	Issues          *validate.Report // Collects the malformed text found
+	Variant         *variant.Filter  // Flags or removes text of the other script variant when not nil
+	Scrub           scrub.Counts     // Replaces emails, phone numbers and URLs with placeholders when not nil
+	Legacy          *bijoy.Converter // Converts Bijoy-encoded lines to Unicode when not nil
 }
 
-func preprocessText(input string) string {
-	// Remove nuktas
-	input = removeNukta(input)
-	// Define the regex for Bangla words
-	banglaWordRegex := regexp.MustCompile(`[\x{0980}-\x{09E5}\x{09F0}-\x{09FF}]+`)
-	// Find all matches in the input string
-	banglaWords := banglaWordRegex.FindAllString(input, -1)
-	return strings.Join(banglaWords, " ")
+// cleanLine validates and cleans one line of input
+func cleanLine(line string, opts CleanOptions) string {
+	if opts.Legacy != nil {
+		line = opts.Legacy.Apply(line)
+	}
+	// Validate before canonicalization, which hides doubled hasantas
+	switch opts.Validate {
+	case "flag":
+		opts.Issues.Add(validate.Check(line))
+	case "repair":
+		var issues []validate.Issue
+		line, issues = validate.Repair(line)
+		opts.Issues.Add(issues)
+	}
+	if opts.Scrub != nil {
+		line = scrub.Scrub(line, opts.Scrub)
+	}
+	// Canonicalize nukta letters, vowel signs, khanda ta and joiners
+	if opts.Audit != nil {
+		line = normalize.Canonicalize(line, opts.Audit)
+	} else {
+		line = opts.Profile.Normalize(line)
+	}
+	if opts.Variant != nil {
+		line = opts.Variant.Apply(line)
+	}
+	return cleanText(line, opts)
+}
+
+// cleanFile cleans a file line by line, so it works on files of any size. When
+// romanized is not nil, it gets the romanization of every output line.
+func cleanFile(inputFile string, output, romanized io.Writer, opts CleanOptions) error {
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
+	reader := bufio.NewReader(f)
+	writer := bufio.NewWriter(output)
+	var romanWriter *bufio.Writer
+	if romanized != nil {
+		romanWriter = bufio.NewWriter(romanized)
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if cleaned := cleanLine(line, opts); cleaned != "" {
+				if _, err := writer.WriteString(cleaned + "\n"); err != nil {
+					return err
+				}
+				if romanWriter != nil {
+					if _, err := romanWriter.WriteString(translit.ToISO15919(cleaned) + "\n"); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if romanWriter != nil {
+		if err := romanWriter.Flush(); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
 }
 
 func main() {
+	inputFile := flag.String("input", "", "Text file to clean (the built-in sample is used when empty)")
+	outputFile := flag.String("output", "", "Output file (stdout when empty)")
+	sentencePerLine := flag.Bool("sentences", false, "Write one sentence per line")
+	auditMode := flag.Bool("audit", false, "Report how often each canonicalization rewrite fired")
+	validateMode := flag.String("validate", "", "Find malformed text: flag (report only) or repair")
+	samplesFile := flag.String("samples", "", "File to write samples of malformed text to")
+	samplesPerKind := flag.Int("samples-per-kind", 50, "Samples to keep per kind of malformed text")
+	lang := flag.String("lang", "bn", "Language profile of the corpus: "+strings.Join(profile.Codes(), ", "))
+	scriptVariant := flag.String("script-variant", "", "Assamese/Bangla text not in --lang: flag, remove, or empty to skip")
+	numeralPolicy := flag.String("numerals", numerals.Drop, "Numeral policy: "+strings.Join(numerals.Policies, ", "))
+	latinMode := flag.String("latin", tokenizer.LatinDrop, "Latin-script words in code-mixed text: keep, lower, tag, or empty to drop")
+	romanizedFile := flag.String("romanized", "", "Also write the ISO 15919 romanization of each output line to this file")
+	scrubPII := flag.Bool("scrub", false, "Replace emails, phone numbers and URLs with <EMAIL>, <PHONE> and <URL>")
+	encoding := flag.String("encoding", bijoy.Unicode, "Encoding of the input: unicode, bijoy, or auto to convert the lines detected as Bijoy")
+	flag.Parse()
+
+	langProfile, err := profile.Get(*lang)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	tokenOpts := tokenizer.Options{Latin: *latinMode}
+	if *scrubPII {
+		tokenOpts.Special = scrub.Tokens
+	}
+	numbers, err := numerals.New(*numeralPolicy, langProfile, tokenOpts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := CleanOptions{Profile: langProfile, Numbers: numbers, SentencePerLine: *sentencePerLine, Validate: *validateMode}
+	if *scrubPII {
+		opts.Scrub = scrub.Counts{}
+	}
+	switch *encoding {
+	case bijoy.Unicode:
+	case bijoy.Bijoy, "auto":
+		if *lang != variant.Bangla && *lang != variant.Assamese {
+			fmt.Printf("Bijoy conversion needs --lang=bn or --lang=as\n")
+			os.Exit(1)
+		}
+		opts.Legacy = &bijoy.Converter{Auto: *encoding == "auto"}
+	default:
+		fmt.Printf("Unknown encoding %q, expected unicode, bijoy or auto\n", *encoding)
+		os.Exit(1)
+	}
+	if *auditMode {
+		if *lang != variant.Bangla && *lang != variant.Assamese {
+			fmt.Printf("Canonicalization audit needs --lang=bn or --lang=as\n")
+			os.Exit(1)
+		}
+		opts.Audit = normalize.Audit{}
+	}
+	switch *validateMode {
+	case "":
+	case "flag", "repair":
+		opts.Issues = validate.NewReport(*samplesPerKind)
+	default:
+		fmt.Printf("Unknown validate mode %q, expected flag or repair\n", *validateMode)
+		os.Exit(1)
+	}
+	switch *scriptVariant {
+	case "":
+	case "flag", "remove":
+		if *lang != variant.Bangla && *lang != variant.Assamese {
+			fmt.Printf("Script-variant filtering needs --lang=bn or --lang=as\n")
+			os.Exit(1)
+		}
+		opts.Variant = variant.NewFilter(*lang, *scriptVariant == "remove")
+	default:
+		fmt.Printf("Unknown script-variant mode %q, expected flag or remove\n", *scriptVariant)
+		os.Exit(1)
+	}
+	var romanized io.Writer
+	if *romanizedFile != "" {
+		if *lang != variant.Bangla && *lang != variant.Assamese {
+			fmt.Printf("Romanization needs --lang=bn or --lang=as\n")
+			os.Exit(1)
+		}
+		f, err := os.Create(*romanizedFile)
+		if err != nil {
+			fmt.Printf("Error creating romanized file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		romanized = f
+	}
+
+	if *inputFile != "" {
+		output := os.Stdout
+		if *outputFile != "" {
+			f, err := os.Create(*outputFile)
+			if err != nil {
+				fmt.Printf("Error creating output file: %v\n", err)
+				os.Exit(1)
+			}
+			defer f.Close()
+			output = f
+		}
+		if err := cleanFile(*inputFile, output, romanized, opts); err != nil {
+			fmt.Printf("Error cleaning file: %v\n", err)
+			os.Exit(1)
+		}
+		printReports(opts, *samplesFile)
+		return
+	}
+
 	// Define the regex for Bangla words
 	// banglaWordRegex := regexp.MustCompile(`[\x{0980}-\x{09E5}\x{09F0}-\x{09FF}]+`)
 
@@ -43,5 +248,38 @@ func main() {
 	// banglaWords := banglaWordRegex.FindAllString(input, -1)
 
 	// Output the extracted Bangla words
-	fmt.Println(preprocessText(input))
+	cleaned := cleanLine(input, opts)
+	fmt.Println(cleaned)
+	if romanized != nil {
+		fmt.Fprintln(romanized, translit.ToISO15919(cleaned))
+	}
+	printReports(opts, *samplesFile)
+}
+
+// printReports prints the conversion, audit, scrub and validation counts to
+// stderr, so they do not mix with cleaned text on stdout
+func printReports(opts CleanOptions, samplesFile string) {
+	if opts.Audit != nil {
+		fmt.Fprintf(os.Stderr, "Canonicalization rewrites:\n%s", opts.Audit)
+	}
+	if opts.Legacy != nil {
+		fmt.Fprintf(os.Stderr, "Converted from Bijoy: %d lines\n", opts.Legacy.Converted)
+	}
+	if opts.Scrub != nil {
+		fmt.Fprintf(os.Stderr, "Scrubbed: %s\n", opts.Scrub)
+	}
+	if opts.Variant != nil {
+		fmt.Fprintf(os.Stderr, "Script variant not %s: %d sentences, %d words\n",
+			opts.Variant.Lang, opts.Variant.SentencesFound, opts.Variant.TokensFound)
+	}
+	if opts.Issues == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Malformed text (%s):\n%s", opts.Validate, opts.Issues)
+	if samplesFile != "" {
+		if err := opts.Issues.WriteSamples(samplesFile); err != nil {
+			fmt.Printf("Error writing samples: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,135 @@
+// Package collocation scores word n-grams by how much more often their words
+// occur together than chance would have it, to tell multiword expressions
+// such as names (শেখ মুজিবুর রহমান) from n-grams that are merely frequent
+// because their words are (এবং তার). Bigrams are scored with pointwise mutual
+// information, the t-score and Dunning's log-likelihood ratio. Trigrams get
+// PMI and t-score against the product of their three words, and the weaker
+// log-likelihood ratio of their two splits (xy|z and x|yz).
+package collocation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+// Measures to rank by
+const (
+	LLR    = "llr"
+	PMI    = "pmi"
+	TScore = "t"
+)
+
+// Measures lists the measures, the default first
+var Measures = []string{LLR, PMI, TScore}
+
+// Collocation is a scored n-gram
+type Collocation struct {
+	NGram  string
+	Count  int
+	PMI    float64 // In bits
+	TScore float64
+	LLR    float64 // G², negative when the words occur together less than expected
+}
+
+// Lookup returns the count of a word or n-gram, 0 when it was not counted
+type Lookup func(ngram string) int
+
+// Score scores a bigram or trigram counted count times. total is the number
+// of word tokens. ok is false when a part of the n-gram has no count, e.g.
+// because it was pruned.
+func Score(ngram string, count int, lookup Lookup, total int) (c Collocation, ok bool) {
+	words := strings.Split(ngram, wordcount.NGramSeparator)
+	if len(words) < 2 || len(words) > 3 || count <= 0 || total <= 0 {
+		return c, false
+	}
+	n := float64(total)
+	expected := n
+	for _, word := range words {
+		wc := lookup(word)
+		if wc <= 0 {
+			return c, false
+		}
+		expected *= float64(wc) / n
+	}
+
+	k := float64(count)
+	c = Collocation{
+		NGram:  ngram,
+		Count:  count,
+		PMI:    math.Log2(k / expected),
+		TScore: (k - expected) / math.Sqrt(k),
+	}
+	if len(words) == 2 {
+		c.LLR = logLikelihood(k, float64(lookup(words[0])), float64(lookup(words[1])), n)
+		return c, true
+	}
+	left := lookup(words[0] + wordcount.NGramSeparator + words[1])
+	right := lookup(words[1] + wordcount.NGramSeparator + words[2])
+	if left <= 0 || right <= 0 {
+		return c, false
+	}
+	c.LLR = math.Min(
+		logLikelihood(k, float64(left), float64(lookup(words[2])), n),
+		logLikelihood(k, float64(lookup(words[0])), float64(right), n))
+	return c, true
+}
+
+// logLikelihood is Dunning's G² of the 2x2 contingency table of two events
+// that occur a and b times out of n, and k times together. It is negative
+// when they occur together less often than independent events would.
+func logLikelihood(k, a, b, n float64) float64 {
+	cells := [4]struct{ observed, row, col float64 }{
+		{k, a, b},
+		{a - k, a, n - b},
+		{b - k, n - a, b},
+		{n - a - b + k, n - a, n - b},
+	}
+	g := 0.0
+	for _, cell := range cells {
+		// Counts of sentence-bounded n-grams can be slightly inconsistent
+		// with the word counts, so cells are clamped at zero
+		if cell.observed > 0 && cell.row > 0 && cell.col > 0 {
+			g += cell.observed * math.Log(cell.observed*n/(cell.row*cell.col))
+		}
+	}
+	g = math.Max(2*g, 0)
+	if k < a*b/n {
+		return -g
+	}
+	return g
+}
+
+// Value returns the score of c by a measure
+func (c Collocation) Value(measure string) float64 {
+	switch measure {
+	case PMI:
+		return c.PMI
+	case TScore:
+		return c.TScore
+	}
+	return c.LLR
+}
+
+// Rank sorts collocations by a measure, best first, then by count and n-gram
+func Rank(collocations []Collocation, measure string) error {
+	switch measure {
+	case LLR, PMI, TScore:
+	default:
+		return fmt.Errorf("unknown measure %q, expected %s", measure, strings.Join(Measures, ", "))
+	}
+	sort.Slice(collocations, func(i, j int) bool {
+		a, b := collocations[i], collocations[j]
+		if va, vb := a.Value(measure), b.Value(measure); va != vb {
+			return va > vb
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.NGram < b.NGram
+	})
+	return nil
+}
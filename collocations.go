@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/collocation"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/numerals"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/scrub"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+func main() {
+	inputPath := flag.String("input", "", "Corpus file to count; without it the count files given as arguments are used")
+	workers := flag.Int("workers", 12, "Number of worker threads")
+	lang := flag.String("lang", "bn", "Language profile: "+strings.Join(profile.Codes(), ", "))
+	numeralPolicy := flag.String("numerals", numerals.Keep, "Numeral policy: "+strings.Join(numerals.Policies, ", ")+"; dropped numbers end the n-grams")
+	latinMode := flag.String("latin", tokenizer.LatinDrop, "Latin-script words in code-mixed text: keep, lower, tag, or empty to drop")
+	maxOrder := flag.Int("order", 3, "Longest n-grams to score, 2 for bigrams only or 3 for bigrams and trigrams")
+	minCount := flag.Int("min-count", 5, "Leave out n-grams counted fewer times than this")
+	skipStopwords := flag.Bool("skip-stopwords", true, "Leave out n-grams that start or end with a stopword")
+	measure := flag.String("rank", collocation.LLR, "Measure to rank by: "+strings.Join(collocation.Measures, ", "))
+	topN := flag.Int("top", 1000, "Number of collocations to output per order")
+	flag.Parse()
+
+	if *maxOrder < 2 || *maxOrder > 3 {
+		fmt.Println("--order must be 2 or 3")
+		os.Exit(1)
+	}
+	if *inputPath == "" && flag.NArg() == 0 {
+		fmt.Println("Usage: go run collocations.go --input=merged.txt [--order=3] [--rank=llr]")
+		fmt.Println("   or: go run collocations.go [--rank=llr] all.counts (from top_word_finder.go --ngram=3 --counts)")
+		os.Exit(1)
+	}
+	if err := collocation.Rank(nil, *measure); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	langProfile, err := profile.Get(*lang)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	phases := wordcount.StartPhases("count")
+	var lookup collocation.Lookup
+	var each func(fn func(ngram string, count int))
+	if *inputPath != "" {
+		numbers, err := numerals.New(*numeralPolicy, langProfile, tokenizer.Options{Latin: *latinMode, Special: scrub.Tokens})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		file, err := wordcount.Open(*inputPath)
+		if err != nil {
+			fmt.Printf("Error opening file: %v\n", err)
+			os.Exit(1)
+		}
+		// Stopwords are counted, they are part of the n-grams around them.
+		// Dropped numbers end the n-grams instead of joining their neighbours.
+		tokenize := wordcount.NGrams(numbers.EachToken, langProfile.Sentences, *maxOrder)
+		counts := wordcount.Count(file.Data, tokenize, wordcount.Options{Workers: *workers}).Merge(*workers)
+		file.Close()
+		lookup = counts.Get
+		each = counts.Each
+	} else {
+		counts := make(map[string]int)
+		if err := wordcount.MergeCountFiles(flag.Args(), func(ngram string, count int) {
+			counts[ngram] = count
+		}); err != nil {
+			fmt.Printf("Error reading count files: %v\n", err)
+			os.Exit(1)
+		}
+		lookup = func(ngram string) int { return counts[ngram] }
+		each = func(fn func(string, int)) {
+			for ngram, count := range counts {
+				fn(ngram, count)
+			}
+		}
+	}
+
+	// Score the frequent n-grams against the word counts
+	phases.Next("score")
+	total := 0
+	each(func(ngram string, count int) {
+		if wordcount.Order(ngram) == 1 {
+			total += count
+		}
+	})
+	byOrder := make(map[int][]collocation.Collocation)
+	each(func(ngram string, count int) {
+		order := wordcount.Order(ngram)
+		if order < 2 || order > *maxOrder || count < *minCount {
+			return
+		}
+		if *skipStopwords && hasStopwordEdge(ngram, langProfile) {
+			return
+		}
+		if c, ok := collocation.Score(ngram, count, lookup, total); ok {
+			byOrder[order] = append(byOrder[order], c)
+		}
+	})
+
+	phases.Next("write")
+	for order := 2; order <= *maxOrder; order++ {
+		collocations := byOrder[order]
+		if err := collocation.Rank(collocations, *measure); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		outputFile := fmt.Sprintf("collocations_%d.tsv", order)
+		if err := writeCollocations(outputFile, collocations[:min(*topN, len(collocations))]); err != nil {
+			fmt.Printf("Error writing to output file: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d-grams: %d scored, top %d by %s written to %s\n", order, len(collocations), min(*topN, len(collocations)), *measure, outputFile)
+	}
+	fmt.Printf("%d word tokens\n%s", total, wordcount.FormatPhases(phases.Stop()))
+}
+
+// hasStopwordEdge reports whether an n-gram starts or ends with a stopword,
+// which makes it a phrase fragment rather than an expression
+func hasStopwordEdge(ngram string, lang *profile.Profile) bool {
+	words := strings.Split(ngram, wordcount.NGramSeparator)
+	return lang.IsStopword(lang.Normalize(words[0])) || lang.IsStopword(lang.Normalize(words[len(words)-1]))
+}
+
+// writeCollocations writes the scored n-grams as TSV
+func writeCollocations(path string, collocations []collocation.Collocation) error {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	writer := bufio.NewWriter(outFile)
+	fmt.Fprintln(writer, "ngram\tcount\tllr\tpmi\tt")
+	for _, c := range collocations {
+		_, err := fmt.Fprintf(writer, "%s\t%d\t%.2f\t%.3f\t%.3f\n", c.NGram, c.Count, c.LLR, c.PMI, c.TScore)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/dedup"
+)
+
+func main() {
+	defaults := dedup.DefaultConfig()
+	inputFile := flag.String("input", "", "Downloader output, one extract per line")
+	outputFile := flag.String("output", "", "Output file for the deduplicated extracts")
+	reportFile := flag.String("report", "", "Optional TSV report of the removed clusters")
+	shingleSize := flag.Int("shingle", defaults.ShingleSize, "Words per shingle")
+	numHashes := flag.Int("hashes", defaults.NumHashes, "MinHash signature length")
+	bands := flag.Int("bands", defaults.Bands, "Number of LSH bands (must divide --hashes)")
+	threshold := flag.Float64("threshold", defaults.Threshold, "Minimum estimated Jaccard similarity for a near duplicate")
+	maskDigits := flag.Bool("mask-digits", defaults.MaskDigits, "Ignore digit differences when comparing pages")
+	flag.Parse()
+
+	if *inputFile == "" || *outputFile == "" {
+		fmt.Println("Usage: go run corpus-dedup.go --input=content.txt --output=dedup.txt [--report=clusters.tsv]")
+		os.Exit(1)
+	}
+
+	deduper, err := dedup.New(dedup.Config{
+		ShingleSize: *shingleSize,
+		NumHashes:   *numHashes,
+		Bands:       *bands,
+		Threshold:   *threshold,
+		MaskDigits:  *maskDigits,
+	})
+	if err != nil {
+		fmt.Printf("Invalid configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	inputHandle, err := os.Open(*inputFile)
+	if err != nil {
+		fmt.Printf("Error opening input file: %v\n", err)
+		os.Exit(1)
+	}
+	defer inputHandle.Close()
+
+	outputHandle, err := os.Create(*outputFile)
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		os.Exit(1)
+	}
+	defer outputHandle.Close()
+
+	// Keep a short preview of every line so the report is readable
+	previews := make(map[int]string)
+
+	reader := bufio.NewReader(inputHandle)
+	writer := bufio.NewWriter(outputHandle)
+	total, kept := 0, 0
+	var empty []int // Lines without words, which are dropped
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			total++
+			line = strings.TrimRight(line, "\r\n")
+			if strings.TrimSpace(line) == "" {
+				empty = append(empty, total)
+			} else if deduper.Add(total, line) {
+				kept++
+				if _, err := writer.WriteString(line + "\n"); err != nil {
+					fmt.Printf("Error writing to output file: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			if *reportFile != "" {
+				previews[total] = preview(line, 60)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Printf("Error reading input file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Printf("Error writing to output file: %v\n", err)
+		os.Exit(1)
+	}
+
+	clusters := deduper.Clusters()
+	exactRemoved, nearRemoved := 0, 0
+	for _, c := range clusters {
+		for _, m := range c.Removed {
+			if m.Exact {
+				exactRemoved++
+			} else {
+				nearRemoved++
+			}
+		}
+	}
+
+	if *reportFile != "" {
+		if err := writeReport(*reportFile, clusters, empty, previews); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Documents: %d, kept: %d, exact duplicates: %d, near duplicates: %d, clusters: %d, empty lines dropped: %d\n",
+		total, kept, exactRemoved, nearRemoved, len(clusters), len(empty))
+}
+
+// writeReport writes one row per removed document, grouped by cluster, and
+// one row per dropped empty line, with kept_line 0
+func writeReport(path string, clusters []dedup.Cluster, empty []int, previews map[int]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "kept_line\tremoved_line\tkind\tsimilarity\tkept_preview\tremoved_preview")
+	for _, c := range clusters {
+		for _, m := range c.Removed {
+			kind := "near"
+			if m.Exact {
+				kind = "exact"
+			}
+			fmt.Fprintf(w, "%d\t%d\t%s\t%.3f\t%s\t%s\n",
+				c.Kept, m.Doc, kind, m.Similarity, previews[c.Kept], previews[m.Doc])
+		}
+	}
+	for _, line := range empty {
+		fmt.Fprintf(w, "0\t%d\tempty\t0.000\t\t\n", line)
+	}
+	return w.Flush()
+}
+
+func preview(text string, maxRunes int) string {
+	runes := []rune(strings.ReplaceAll(text, "\t", " "))
+	if len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return string(runes)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/numerals"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/scrub"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/stats"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+func main() {
+	inputFile := flag.String("input", "", "Corpus file, one document per line")
+	jsonFile := flag.String("output", "corpus-stats.json", "JSON report")
+	htmlFile := flag.String("html", "corpus-stats.html", "HTML report (none when empty)")
+	compareFile := flag.String("compare", "", "JSON report of a previous corpus version to compare with in the HTML report")
+	workers := flag.Int("workers", 12, "Number of worker threads")
+	lang := flag.String("lang", "bn", "Language profile: "+strings.Join(profile.Codes(), ", "))
+	numeralPolicy := flag.String("numerals", numerals.Keep, "Numeral policy: "+strings.Join(numerals.Policies, ", "))
+	latinMode := flag.String("latin", tokenizer.LatinDrop, "Latin-script words in code-mixed text: keep, lower, tag, or empty to drop")
+	heapsPoints := flag.Int("heaps-points", 100, "Points of the Heaps' law curve")
+	topN := flag.Int("top", 20, "Most frequent words to list")
+	flag.Parse()
+
+	if *inputFile == "" {
+		fmt.Println("Usage: go run corpus-stats.go --input=merged.txt [--output=corpus-stats.json] [--html=corpus-stats.html] [--compare=old-stats.json]")
+		os.Exit(1)
+	}
+	langProfile, err := profile.Get(*lang)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	numbers, err := numerals.New(*numeralPolicy, langProfile, tokenizer.Options{Latin: *latinMode, Special: scrub.Tokens})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	var previous *stats.Report
+	if *compareFile != "" {
+		if previous, err = readReport(*compareFile); err != nil {
+			fmt.Printf("Error reading %s: %v\n", *compareFile, err)
+			os.Exit(1)
+		}
+	}
+
+	file, err := wordcount.Open(*inputFile)
+	if err != nil {
+		fmt.Printf("Error opening file: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	// Workers send the size of each chunk to a single monitor, which prints
+	progress := make(chan int64, max(*workers, 1))
+	monitorDone := make(chan struct{})
+	startTime := time.Now()
+	go func() {
+		var processed int64
+		total := float64(len(file.Data))
+		for bytes := range progress {
+			processed += bytes
+			done := float64(processed)
+			fmt.Printf("\rProgress: %.2f%% (%.2f MB/s)", done/total*100, done/(1024*1024*time.Since(startTime).Seconds()))
+		}
+		fmt.Println()
+		close(monitorDone)
+	}()
+	report := stats.Compute(file.Data, stats.Options{
+		Workers:     *workers,
+		Tokenize:    numbers.Each,
+		Sentences:   langProfile.Sentences,
+		Script:      langProfile.Script,
+		ScriptName:  langProfile.Name,
+		HeapsPoints: *heapsPoints,
+		TopWords:    *topN,
+		Progress:    func(bytes int64) { progress <- bytes },
+	})
+	close(progress)
+	<-monitorDone
+	report.Corpus = *inputFile
+
+	if err := writeReport(*jsonFile, report); err != nil {
+		fmt.Printf("Error writing JSON report: %v\n", err)
+		os.Exit(1)
+	}
+	if *htmlFile != "" {
+		f, err := os.Create(*htmlFile)
+		if err != nil {
+			fmt.Printf("Error creating HTML report: %v\n", err)
+			os.Exit(1)
+		}
+		err = stats.WriteHTML(f, report, previous)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			fmt.Printf("Error writing HTML report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	fmt.Printf("Documents: %d, sentences: %d, tokens: %d, types: %d, hapax: %d, TTR: %.5f\n",
+		report.Documents, report.Sentences, report.Tokens, report.Types, report.Hapax, report.TypeTokenRatio)
+	fmt.Printf("Zipf slope: %.3f (R² %.3f), Heaps: K=%.2f β=%.3f\n", report.ZipfSlope, report.ZipfR2, report.HeapsK, report.HeapsBeta)
+	fmt.Printf("Report written to %s", *jsonFile)
+	if *htmlFile != "" {
+		fmt.Printf(" and %s", *htmlFile)
+	}
+	fmt.Printf(" in %v\n", time.Since(startTime).Round(time.Millisecond))
+}
+
+func readReport(path string) (*stats.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var report stats.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func writeReport(path string, report *stats.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
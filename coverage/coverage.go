@@ -0,0 +1,245 @@
+// Package coverage tells how much of a corpus the most frequent words cover,
+// to choose a vocabulary size, and how much of a held-out corpus a given
+// vocabulary misses.
+package coverage
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+// Output formats. Text is the "count word" format of the top word lists.
+const (
+	Text = "text"
+	CSV  = "csv"
+	TSV  = "tsv"
+	JSON = "json"
+)
+
+// Formats lists the formats, the default first
+var Formats = []string{Text, CSV, TSV, JSON}
+
+// DefaultLevels are the coverages to report the vocabulary size for
+var DefaultLevels = []float64{0.90, 0.95, 0.99}
+
+// Row is a word of the coverage table
+type Row struct {
+	Rank      int     `json:"rank"`
+	Word      string  `json:"word"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"relative_frequency"`
+	Coverage  float64 `json:"cumulative_coverage"` // Share of all tokens covered by the words up to this rank
+}
+
+// Level is the number of most frequent words needed to cover a share of the
+// tokens
+type Level struct {
+	Coverage float64 `json:"coverage"`
+	Words    int     `json:"words"`
+}
+
+// Report is the coverage of the top words of a corpus
+type Report struct {
+	Tokens int64   `json:"tokens"`
+	Types  int     `json:"types"`
+	Levels []Level `json:"levels"`
+	Words  []Row   `json:"words"`
+}
+
+// NewReport builds the coverage table of the top words, sorted most
+// frequent first. types holds the number of words seen with each count, for
+// all words, so that its size does not grow with the vocabulary.
+func NewReport(top []wordcount.WordCount, types map[int]int, levels []float64) *Report {
+	r := &Report{}
+	for count, n := range types {
+		r.Types += n
+		r.Tokens += int64(count) * int64(n)
+	}
+	if r.Tokens == 0 {
+		return r
+	}
+	total := float64(r.Tokens)
+	var covered int64
+	for i, wc := range top {
+		covered += int64(wc.Count)
+		r.Words = append(r.Words, Row{
+			Rank:      i + 1,
+			Word:      wc.Word,
+			Count:     wc.Count,
+			Frequency: float64(wc.Count) / total,
+			Coverage:  float64(covered) / total,
+		})
+	}
+	for _, level := range levels {
+		r.Levels = append(r.Levels, Level{Coverage: level, Words: WordsFor(types, r.Tokens, level)})
+	}
+	return r
+}
+
+// WordsFor returns how many of the most frequent words cover at least level
+// of the tokens. types holds the number of words seen with each count.
+func WordsFor(types map[int]int, tokens int64, level float64) int {
+	counts := make([]int, 0, len(types))
+	for count := range types {
+		counts = append(counts, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	target := level * float64(tokens)
+	var covered int64
+	words := 0
+	for _, count := range counts {
+		n := types[count]
+		if float64(covered+int64(count)*int64(n)) >= target {
+			// Only some of the words with this count are needed
+			need := int(math.Ceil((target - float64(covered)) / float64(count)))
+			return words + min(max(need, 1), n)
+		}
+		covered += int64(count) * int64(n)
+		words += n
+	}
+	return words
+}
+
+// Write writes the table in a format
+func (r *Report) Write(w io.Writer, format string) error {
+	writer := bufio.NewWriter(w)
+	switch format {
+	case Text:
+		for _, row := range r.Words {
+			if _, err := fmt.Fprintf(writer, "%d %s\n", row.Count, row.Word); err != nil {
+				return err
+			}
+		}
+	case CSV, TSV:
+		cw := csv.NewWriter(writer)
+		if format == TSV {
+			cw.Comma = '\t'
+		}
+		cw.Write([]string{"rank", "word", "count", "relative_frequency", "cumulative_coverage"})
+		for _, row := range r.Words {
+			cw.Write([]string{
+				strconv.Itoa(row.Rank),
+				row.Word,
+				strconv.Itoa(row.Count),
+				strconv.FormatFloat(row.Frequency, 'g', 6, 64),
+				strconv.FormatFloat(row.Coverage, 'f', 6, 64),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	case JSON:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	default:
+		return ValidateFormat(format)
+	}
+	return writer.Flush()
+}
+
+// ValidateFormat checks a format name
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, expected %s", format, strings.Join(Formats, ", "))
+}
+
+// ReadVocab reads the words of a vocabulary file: one word per line, alone
+// or with its count, as in word2vec, fastText and GloVe vocabularies ("word
+// count") and in the top word lists ("count word"). The header of a CSV or
+// TSV coverage table is skipped and its word column used.
+func ReadVocab(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	words := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	column, separator := -1, ""
+	// Lines of two numbers, such as "15 2024", wait until another line shows
+	// which of the two columns holds the word
+	wordColumn := -1
+	var pending [][]string
+	for first := true; scanner.Scan(); first = false {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first && strings.HasPrefix(line, "rank") {
+			if column, separator = headerColumn(line); column >= 0 {
+				continue
+			}
+		}
+		if column >= 0 {
+			if fields := strings.Split(line, separator); column < len(fields) {
+				words[fields[column]] = true
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case len(fields) == 2 && isCount(fields[0]) && isCount(fields[1]):
+			if wordColumn < 0 {
+				pending = append(pending, fields)
+			} else {
+				words[fields[wordColumn]] = true
+			}
+		case len(fields) == 2 && isCount(fields[0]):
+			wordColumn = 1 // "count word"
+			words[fields[1]] = true
+		case len(fields) == 2 && isCount(fields[1]):
+			wordColumn = 0 // "word count"
+			words[fields[0]] = true
+		default:
+			words[fields[0]] = true
+		}
+		if wordColumn >= 0 && len(pending) > 0 {
+			for _, fields := range pending {
+				words[fields[wordColumn]] = true
+			}
+			pending = nil
+		}
+	}
+	// Without a hint, numbers are words of a trainer vocabulary
+	for _, fields := range pending {
+		words[fields[0]] = true
+	}
+	return words, scanner.Err()
+}
+
+// headerColumn returns the index of the word column of a CSV or TSV header
+// and its separator, or -1
+func headerColumn(header string) (int, string) {
+	for _, sep := range []string{"\t", ","} {
+		for i, name := range strings.Split(header, sep) {
+			if name == "word" {
+				return i, sep
+			}
+		}
+	}
+	return -1, ""
+}
+
+func isCount(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
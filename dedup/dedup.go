@@ -0,0 +1,236 @@
+// Package dedup finds exact and near-duplicate documents. Exact copies are
+// caught by hashing the whitespace-normalized text, near copies by comparing
+// MinHash signatures of word shingles that are bucketed with
+// locality-sensitive hashing (LSH).
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Config controls how similar two documents must be to count as duplicates
+type Config struct {
+	ShingleSize int     // Number of words per shingle
+	NumHashes   int     // Length of the MinHash signature
+	Bands       int     // Number of LSH bands, must divide NumHashes
+	Threshold   float64 // Minimum estimated Jaccard similarity for a near duplicate
+	MaskDigits  bool    // Treat all digits as equal so numbered stubs collide
+}
+
+// DefaultConfig returns settings that work well for Wikipedia extracts
+func DefaultConfig() Config {
+	return Config{
+		ShingleSize: 5,
+		NumHashes:   128,
+		Bands:       32,
+		Threshold:   0.8,
+		MaskDigits:  true,
+	}
+}
+
+// Match is a removed document and its similarity to the kept one
+type Match struct {
+	Doc        int
+	Similarity float64
+	Exact      bool
+}
+
+// Cluster groups every removed document under the document that was kept
+type Cluster struct {
+	Kept    int
+	Removed []Match
+}
+
+// Deduper keeps the first document of every duplicate cluster. Documents are
+// added in stream order, so only the signatures of kept documents are held.
+type Deduper struct {
+	cfg      Config
+	rows     int
+	seeds    []uint64
+	exact    map[uint64]int
+	sigs     map[int][]uint32
+	buckets  []map[uint64][]int
+	clusters map[int]*Cluster
+}
+
+// New creates a Deduper after validating the config
+func New(cfg Config) (*Deduper, error) {
+	if cfg.ShingleSize < 1 {
+		return nil, fmt.Errorf("shingle size must be positive, got %d", cfg.ShingleSize)
+	}
+	if cfg.NumHashes < 1 || cfg.Bands < 1 || cfg.NumHashes%cfg.Bands != 0 {
+		return nil, fmt.Errorf("bands (%d) must evenly divide hashes (%d)", cfg.Bands, cfg.NumHashes)
+	}
+	if cfg.Threshold <= 0 || cfg.Threshold > 1 {
+		return nil, fmt.Errorf("threshold must be in (0, 1], got %v", cfg.Threshold)
+	}
+
+	seeds := make([]uint64, cfg.NumHashes)
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		state = mix64(state + uint64(i))
+		seeds[i] = state
+	}
+
+	buckets := make([]map[uint64][]int, cfg.Bands)
+	for i := range buckets {
+		buckets[i] = make(map[uint64][]int)
+	}
+
+	return &Deduper{
+		cfg:      cfg,
+		rows:     cfg.NumHashes / cfg.Bands,
+		seeds:    seeds,
+		exact:    make(map[uint64]int),
+		sigs:     make(map[int][]uint32),
+		buckets:  buckets,
+		clusters: make(map[int]*Cluster),
+	}, nil
+}
+
+// Add checks a document against all previously kept documents. It returns
+// true if the document should be kept and false if it is a duplicate.
+func (d *Deduper) Add(id int, text string) bool {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return false
+	}
+
+	// Exact duplicates
+	contentHash := hashString(strings.Join(words, " "))
+	if kept, ok := d.exact[contentHash]; ok {
+		d.record(kept, Match{Doc: id, Similarity: 1, Exact: true})
+		return false
+	}
+
+	// Near duplicates
+	sig := d.signature(words)
+	bandKeys := make([]uint64, d.cfg.Bands)
+	best, bestSim := -1, 0.0
+	seen := make(map[int]bool)
+	for b := 0; b < d.cfg.Bands; b++ {
+		bandKeys[b] = hashBand(sig[b*d.rows : (b+1)*d.rows])
+		for _, candidate := range d.buckets[b][bandKeys[b]] {
+			if seen[candidate] {
+				continue
+			}
+			seen[candidate] = true
+			sim := similarity(sig, d.sigs[candidate])
+			if sim > bestSim || (sim == bestSim && candidate < best) {
+				best, bestSim = candidate, sim
+			}
+		}
+	}
+	if best >= 0 && bestSim >= d.cfg.Threshold {
+		d.record(best, Match{Doc: id, Similarity: bestSim})
+		return false
+	}
+
+	d.exact[contentHash] = id
+	d.sigs[id] = sig
+	for b, key := range bandKeys {
+		d.buckets[b][key] = append(d.buckets[b][key], id)
+	}
+	return true
+}
+
+// Clusters returns the removed clusters ordered by kept document
+func (d *Deduper) Clusters() []Cluster {
+	clusters := make([]Cluster, 0, len(d.clusters))
+	for _, c := range d.clusters {
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Kept < clusters[j].Kept
+	})
+	return clusters
+}
+
+func (d *Deduper) record(kept int, m Match) {
+	c, ok := d.clusters[kept]
+	if !ok {
+		c = &Cluster{Kept: kept}
+		d.clusters[kept] = c
+	}
+	c.Removed = append(c.Removed, m)
+}
+
+// signature computes the MinHash signature over word shingles
+func (d *Deduper) signature(words []string) []uint32 {
+	if d.cfg.MaskDigits {
+		masked := make([]string, len(words))
+		for i, w := range words {
+			masked[i] = maskDigits(w)
+		}
+		words = masked
+	}
+
+	sig := make([]uint32, d.cfg.NumHashes)
+	for i := range sig {
+		sig[i] = ^uint32(0)
+	}
+
+	n := d.cfg.ShingleSize
+	if len(words) < n {
+		n = len(words)
+	}
+	for start := 0; start+n <= len(words); start++ {
+		shingle := hashString(strings.Join(words[start:start+n], " "))
+		for i, seed := range d.seeds {
+			h := uint32(mix64(shingle^seed) >> 32)
+			if h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// similarity estimates the Jaccard similarity of two signatures
+func similarity(a, b []uint32) float64 {
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a))
+}
+
+func maskDigits(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '0'
+		}
+		return r
+	}, word)
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func hashBand(rows []uint32) uint64 {
+	h := uint64(14695981039346656037)
+	for _, v := range rows {
+		h ^= uint64(v)
+		h *= 1099511628211
+	}
+	return h
+}
+
+// mix64 is the splitmix64 finalizer
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
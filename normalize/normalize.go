@@ -0,0 +1,172 @@
+// Package normalize brings Bangla text into one canonical encoding: nukta
+// letters, two-part vowel signs and আ typed as অ + া are precomposed, khanda
+// ta is written as ৎ, combining marks are ordered and deduplicated, and
+// invisible or stray zero-width characters are removed.
+package normalize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Names of the rewrites, as reported in an Audit
+const (
+	RuleInvisible     = "invisible_char"
+	RuleMarkOrder     = "mark_order"
+	RuleDuplicateMark = "duplicate_mark"
+	RuleNukta         = "nukta"
+	RuleTwoPartVowel  = "two_part_vowel"
+	RuleSplitVowel    = "split_vowel_letter"
+	RuleKhandaTa      = "khanda_ta"
+	RuleStrayJoiner   = "stray_joiner"
+)
+
+const (
+	hasanta = '\u09CD'
+	zwnj    = '\u200C'
+	zwj     = '\u200D'
+)
+
+// compositions are applied in order after the marks are sorted
+var compositions = []struct {
+	rule, from, to string
+}{
+	{RuleNukta, "\u09A1\u09BC", "\u09DC"},          // ড + ় = ড়
+	{RuleNukta, "\u09A2\u09BC", "\u09DD"},          // ঢ + ় = ঢ়
+	{RuleNukta, "\u09AF\u09BC", "\u09DF"},          // য + ় = য়
+	{RuleTwoPartVowel, "\u09C7\u09BE", "\u09CB"},   // ে + া = ো
+	{RuleTwoPartVowel, "\u09C7\u09D7", "\u09CC"},   // ে + ৗ = ৌ
+	{RuleSplitVowel, "\u0985\u09BE", "\u0986"},     // অ + া = আ
+	{RuleKhandaTa, "\u09A4\u09CD\u200D", "\u09CE"}, // ত + ্ + ZWJ = ৎ
+}
+
+// Audit counts how often each rewrite fired
+type Audit map[string]int
+
+// String formats the counts, most frequent first
+func (a Audit) String() string {
+	rules := make([]string, 0, len(a))
+	for rule := range a {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if a[rules[i]] != a[rules[j]] {
+			return a[rules[i]] > a[rules[j]]
+		}
+		return rules[i] < rules[j]
+	})
+	var b strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "%d %s\n", a[rule], rule)
+	}
+	return b.String()
+}
+
+// Canonicalize returns text in canonical form. If audit is not nil, every
+// rewrite is counted in it.
+func Canonicalize(text string, audit Audit) string {
+	runes := []rune(text)
+	runes = removeInvisible(runes, audit)
+	runes = sortMarks(runes, audit)
+	runes = dedupeMarks(runes, audit)
+
+	text = string(runes)
+	for _, c := range compositions {
+		if n := strings.Count(text, c.from); n > 0 {
+			text = strings.ReplaceAll(text, c.from, c.to)
+			count(audit, c.rule, n)
+		}
+	}
+
+	return string(removeStrayJoiners([]rune(text), audit))
+}
+
+// removeInvisible drops zero-width space, BOM, word joiner and soft hyphen
+func removeInvisible(runes []rune, audit Audit) []rune {
+	out := runes[:0]
+	for _, r := range runes {
+		switch r {
+		case '\u200B', '\uFEFF', '\u2060', '\u00AD':
+			count(audit, RuleInvisible, 1)
+		default:
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// markRank orders the combining marks after a base letter: nukta first, then
+// hasanta or vowel signs, then candrabindu, anusvara and visarga
+func markRank(r rune) int {
+	switch {
+	case r == '\u09BC': // nukta
+		return 0
+	case r == hasanta || (r >= '\u09BE' && r <= '\u09CC') || r == '\u09D7' || r == '\u09E2' || r == '\u09E3':
+		return 1
+	case r == '\u0981': // candrabindu
+		return 2
+	case r == '\u0982' || r == '\u0983': // anusvara, visarga
+		return 3
+	}
+	return -1
+}
+
+// sortMarks stably sorts every run of Bangla combining marks by markRank
+func sortMarks(runes []rune, audit Audit) []rune {
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && markRank(runes[j]) >= 0 {
+			j++
+		}
+		if j-i > 1 {
+			run := runes[i:j]
+			if !sort.SliceIsSorted(run, func(a, b int) bool { return markRank(run[a]) < markRank(run[b]) }) {
+				sort.SliceStable(run, func(a, b int) bool { return markRank(run[a]) < markRank(run[b]) })
+				count(audit, RuleMarkOrder, 1)
+			}
+		}
+		if j == i {
+			j++
+		}
+		i = j
+	}
+	return runes
+}
+
+// dedupeMarks collapses repeated identical combining marks
+func dedupeMarks(runes []rune, audit Audit) []rune {
+	out := runes[:0]
+	for i, r := range runes {
+		if i > 0 && r == runes[i-1] && markRank(r) >= 0 {
+			count(audit, RuleDuplicateMark, 1)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// removeStrayJoiners drops ZWJ/ZWNJ unless they sit next to a hasanta, where
+// they select a conjunct form (র‍্য, ক্‌ষ)
+func removeStrayJoiners(runes []rune, audit Audit) []rune {
+	out := make([]rune, 0, len(runes))
+	for i, r := range runes {
+		if r == zwj || r == zwnj {
+			prevHasanta := i > 0 && runes[i-1] == hasanta
+			nextHasanta := i+1 < len(runes) && runes[i+1] == hasanta
+			if !prevHasanta && !nextHasanta {
+				count(audit, RuleStrayJoiner, 1)
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func count(audit Audit, rule string, n int) {
+	if audit != nil {
+		audit[rule] += n
+	}
+}
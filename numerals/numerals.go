@@ -0,0 +1,122 @@
+// Package numerals applies one numeral policy to words and numbers, so that
+// the cleaner and the word counter treat "১৭ কোটি" and "17 কোটি" the same way.
+package numerals
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+)
+
+// Policies
+const (
+	Drop   = "drop"   // Remove numbers with the word attached to them (৫৭টি, ১৯৪৭-এ)
+	Keep   = "keep"   // Keep numbers as written
+	ASCII  = "ascii"  // Write all numbers with ASCII digits
+	Native = "native" // Write all numbers with the digits of the language
+	Mask   = "mask"   // Replace every number with NumToken
+)
+
+// NumToken replaces numbers under the Mask policy
+const NumToken = "<NUM>"
+
+// Policies lists the valid policy names
+var Policies = []string{Drop, Keep, ASCII, Native, Mask}
+
+// Policy tokenizes text and rewrites the numbers in it
+type Policy struct {
+	Mode  string
+	zero  rune
+	words *tokenizer.Tokenizer
+}
+
+// New creates a policy for the given mode and language. The other tokenizer
+// options, such as what to do with Latin words, are passed through.
+func New(mode string, lang *profile.Profile, opts tokenizer.Options) (*Policy, error) {
+	switch mode {
+	case Drop, Keep, ASCII, Native, Mask:
+	default:
+		return nil, fmt.Errorf("unknown numeral policy %q, expected one of %s", mode, strings.Join(Policies, ", "))
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	// Dropped numbers are still tokenized, so that the words attached to
+	// them go too instead of leaving a bare suffix such as "টি"
+	opts.Numbers = true
+	opts.Suffixes = mode == Drop
+	// Numbers masked in an earlier pass stay whole
+	opts.Special = append(opts.Special[:len(opts.Special):len(opts.Special)], NumToken)
+	return &Policy{
+		Mode:  mode,
+		zero:  lang.ZeroDigit,
+		words: lang.Tokenizer(opts),
+	}, nil
+}
+
+// Each calls fn for every word and, unless dropped, every rewritten number
+func (p *Policy) Each(text string, fn func(token string)) {
+	p.EachToken(text, func(token string, keep bool) {
+		if keep {
+			fn(token)
+		}
+	})
+}
+
+// EachToken calls fn for every word and rewritten number, and for every
+// dropped number with keep false, so that callers can tell where one was
+func (p *Policy) EachToken(text string, fn func(token string, keep bool)) {
+	p.words.Each(text, func(token string) {
+		if IsNumber(token) {
+			if p.Mode == Drop {
+				fn(token, false)
+				return
+			}
+			token = p.Convert(token)
+		}
+		fn(token, true)
+	})
+}
+
+// Words returns the words and rewritten numbers in text
+func (p *Policy) Words(text string) []string {
+	var words []string
+	p.Each(text, func(token string) {
+		words = append(words, token)
+	})
+	return words
+}
+
+// Convert rewrites a number according to the policy
+func (p *Policy) Convert(number string) string {
+	switch p.Mode {
+	case Mask:
+		return NumToken
+	case ASCII:
+		return strings.Map(func(r rune) rune {
+			if r >= p.zero && r <= p.zero+9 {
+				return '0' + (r - p.zero)
+			}
+			return r
+		}, number)
+	case Native:
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return p.zero + (r - '0')
+			}
+			return r
+		}, number)
+	}
+	return number
+}
+
+// IsNumber reports whether a token starts with a digit. Tokens from a
+// tokenizer with Numbers set are then numbers throughout.
+func IsNumber(token string) bool {
+	r, _ := utf8.DecodeRuneInString(token)
+	return unicode.IsDigit(r)
+}
@@ -0,0 +1,191 @@
+// Package profile describes the languages the corpus tools support: their
+// script, digits, sentence terminators, normalization and stopwords. Tools
+// pick a profile by Wikipedia language code instead of hardcoding Bangla.
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/segmenter"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+)
+
+// Replacement rewrites one encoding of a letter into the canonical one
+type Replacement struct {
+	From, To string
+}
+
+// Profile holds everything language-specific
+type Profile struct {
+	Code          string
+	Name          string
+	Script        *unicode.RangeTable
+	ZeroDigit     rune // The digits of the script are ZeroDigit to ZeroDigit+9
+	Terminators   []rune
+	Abbreviations []string
+	Replacements  []Replacement // Used when Canonicalize is nil
+	Canonicalize  func(text string) string
+	Stopwords     []string
+
+	once      sync.Once
+	words     *tokenizer.Tokenizer
+	sentences *segmenter.Segmenter
+	stopwords map[string]bool
+}
+
+// Devanagari nukta letters are written precomposed, like the Bangla ones
+var devanagariNukta = []Replacement{
+	{"\u0915\u093C", "\u0958"}, // क़
+	{"\u0916\u093C", "\u0959"}, // ख़
+	{"\u0917\u093C", "\u095A"}, // ग़
+	{"\u091C\u093C", "\u095B"}, // ज़
+	{"\u0921\u093C", "\u095C"}, // ड़
+	{"\u0922\u093C", "\u095D"}, // ढ़
+	{"\u092B\u093C", "\u095E"}, // फ़
+	{"\u092F\u093C", "\u095F"}, // य़
+}
+
+func canonicalizeBengali(text string) string {
+	return normalize.Canonicalize(text, nil)
+}
+
+var profiles = map[string]*Profile{
+	"bn": {
+		Code:          "bn",
+		Name:          "Bangla",
+		Script:        unicode.Bengali,
+		ZeroDigit:     '০',
+		Terminators:   []rune{'।', '॥', '?', '!'},
+		Abbreviations: segmenter.BanglaAbbreviations,
+		Canonicalize:  canonicalizeBengali,
+		Stopwords: []string{
+			"এবং", "ও", "করে", "এই", "একটি", "হয়", "থেকে", "তার", "না", "এর",
+			"যে", "জন্য", "সঙ্গে", "তিনি", "করা", "হয়ে", "ছিল", "হবে", "কিন্তু",
+			"বা", "তাদের", "এক", "সেই", "আর", "পর", "মধ্যে", "করেন", "নিয়ে",
+		},
+	},
+	"as": {
+		Code:         "as",
+		Name:         "Assamese",
+		Script:       unicode.Bengali,
+		ZeroDigit:    '০',
+		Terminators:  []rune{'।', '॥', '?', '!'},
+		Canonicalize: canonicalizeBengali,
+		Stopwords: []string{
+			"আৰু", "এই", "এটা", "কৰা", "হয়", "যে", "বুলি", "তেওঁ", "সকলো", "লগত",
+			"পৰা", "বাবে", "নহয়", "আছিল", "কিন্তু", "হৈছে", "তেওঁৰ", "এখন",
+		},
+	},
+	"hi": {
+		Code:         "hi",
+		Name:         "Hindi",
+		Script:       unicode.Devanagari,
+		ZeroDigit:    '०',
+		Terminators:  []rune{'।', '॥', '?', '!'},
+		Replacements: devanagariNukta,
+		Stopwords: []string{
+			"और", "का", "की", "के", "में", "है", "से", "को", "पर", "यह",
+			"एक", "था", "हैं", "ने", "भी", "कि", "तो", "नहीं", "लिए", "हो",
+		},
+	},
+	"mr": {
+		Code:         "mr",
+		Name:         "Marathi",
+		Script:       unicode.Devanagari,
+		ZeroDigit:    '०',
+		Terminators:  []rune{'।', '॥', '?', '!'},
+		Replacements: devanagariNukta,
+		Stopwords: []string{
+			"आणि", "आहे", "या", "हे", "व", "ते", "की", "एक", "होते", "मध्ये",
+			"हा", "ही", "तो", "आहेत", "केले", "त्या", "न", "पण",
+		},
+	},
+	"or": {
+		Code:        "or",
+		Name:        "Odia",
+		Script:      unicode.Oriya,
+		ZeroDigit:   '୦',
+		Terminators: []rune{'।', '॥', '?', '!'},
+		Replacements: []Replacement{
+			{"\u0B21\u0B3C", "\u0B5C"}, // ଡ଼
+			{"\u0B22\u0B3C", "\u0B5D"}, // ଢ଼
+		},
+		Stopwords: []string{
+			"ଓ", "ଏବଂ", "ଏହି", "ଏକ", "ଯେ", "କରି", "ହୋଇ", "ସହ", "ପାଇଁ", "ଥିଲା",
+			"ନାହିଁ", "କରିଥିଲେ", "ଏହା", "ସେ", "ବା",
+		},
+	},
+}
+
+// Get returns the profile for a language code
+func Get(code string) (*Profile, error) {
+	p, ok := profiles[code]
+	if !ok {
+		return nil, fmt.Errorf("no language profile for %q, supported: %s", code, strings.Join(Codes(), ", "))
+	}
+	p.init()
+	return p, nil
+}
+
+// Codes returns the supported language codes
+func Codes() []string {
+	codes := make([]string, 0, len(profiles))
+	for code := range profiles {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func (p *Profile) init() {
+	p.once.Do(func() {
+		p.words = p.Tokenizer(tokenizer.Options{})
+		p.sentences = segmenter.New(p.Terminators, p.Abbreviations)
+		p.stopwords = make(map[string]bool, len(p.Stopwords))
+		for _, w := range p.Stopwords {
+			p.stopwords[p.Normalize(w)] = true
+		}
+	})
+}
+
+// Normalize brings text into the canonical encoding of the language
+func (p *Profile) Normalize(text string) string {
+	if p.Canonicalize != nil {
+		return p.Canonicalize(text)
+	}
+	for _, r := range p.Replacements {
+		text = strings.ReplaceAll(text, r.From, r.To)
+	}
+	return text
+}
+
+// Tokenizer returns a word tokenizer for the script of the language
+func (p *Profile) Tokenizer(opts tokenizer.Options) *tokenizer.Tokenizer {
+	opts.Script = p.Script
+	return tokenizer.New(opts)
+}
+
+// Words returns the words of the language in text, without digits
+func (p *Profile) Words(text string) []string {
+	return p.words.Words(text)
+}
+
+// Sentences splits text into sentences
+func (p *Profile) Sentences(text string) []string {
+	return p.sentences.Split(text)
+}
+
+// IsStopword reports whether a normalized word is a stopword
+func (p *Profile) IsStopword(word string) bool {
+	return p.stopwords[word]
+}
+
+// IsDigit reports whether r is a digit of the script
+func (p *Profile) IsDigit(r rune) bool {
+	return r >= p.ZeroDigit && r <= p.ZeroDigit+9
+}
@@ -0,0 +1,149 @@
+// Package quality decides whether a fetched Wikipedia page is good enough to
+// go into the corpus.
+package quality
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Reasons a page can be dropped for
+const (
+	ReasonDisambiguation = "disambiguation"
+	ReasonList           = "list"
+	ReasonTooShort       = "too_short"
+	ReasonLowBangla      = "low_bangla_ratio"
+)
+
+// Config holds the filter thresholds. Zero values disable the filter.
+type Config struct {
+	MinWords           int                 // Minimum words in the cleaned text
+	MinBanglaRatio     float64             // Minimum share of letters in Script among all letters
+	Script             *unicode.RangeTable // Script of the corpus, Bengali when nil
+	DropDisambiguation bool                // Drop pages that carry the disambiguation page prop
+	DropLists          bool                // Drop list pages
+	ListLineRatio      float64             // Share of short lines above which a page is a list
+}
+
+// DefaultConfig returns the filters used by the downloader
+func DefaultConfig() Config {
+	return Config{
+		MinWords:           20,
+		MinBanglaRatio:     0.5,
+		DropDisambiguation: true,
+		DropLists:          true,
+		ListLineRatio:      0.6,
+	}
+}
+
+// Page is what the filters look at
+type Page struct {
+	Title          string
+	Extract        string // Raw extract from the API
+	Cleaned        string // Extract after preprocessing
+	Disambiguation bool   // Set when pageprops contains disambiguation
+}
+
+// DroppedError is returned for pages rejected by a filter
+type DroppedError struct {
+	Title  string
+	Reason string
+}
+
+func (e *DroppedError) Error() string {
+	return fmt.Sprintf("page %s dropped: %s", e.Title, e.Reason)
+}
+
+// Check returns a *DroppedError if the page fails any filter
+func (c Config) Check(p Page) error {
+	reason := ""
+	switch {
+	case c.DropDisambiguation && p.Disambiguation:
+		reason = ReasonDisambiguation
+	case c.DropLists && isList(p, c.ListLineRatio):
+		reason = ReasonList
+	case c.MinWords > 0 && len(strings.Fields(p.Cleaned)) < c.MinWords:
+		reason = ReasonTooShort
+	case c.MinBanglaRatio > 0 && ScriptRatio(p.Extract, c.Script) < c.MinBanglaRatio:
+		reason = ReasonLowBangla
+	}
+	if reason == "" {
+		return nil
+	}
+	return &DroppedError{Title: p.Title, Reason: reason}
+}
+
+// ScriptRatio returns the share of letters and signs in the text that belong
+// to script, or to the Bengali script if script is nil
+func ScriptRatio(text string, script *unicode.RangeTable) float64 {
+	if script == nil {
+		script = unicode.Bengali
+	}
+	inScript, total := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+			continue
+		}
+		total++
+		if unicode.Is(script, r) {
+			inScript++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(inScript) / float64(total)
+}
+
+// isList flags pages titled as lists ("...র তালিকা", "...তালিকাসমূহ", "List
+// of ...") and pages whose body is mostly short lines
+func isList(p Page, lineRatio float64) bool {
+	title := strings.ToLower(strings.ReplaceAll(p.Title, "_", " "))
+	for _, word := range strings.Fields(title) {
+		// Also inflected and plural forms: তালিকার, তালিকাসমূহ, তালিকাগুলি
+		if strings.HasPrefix(word, "তালিকা") {
+			return true
+		}
+	}
+	if strings.HasPrefix(title, "list of ") {
+		return true
+	}
+
+	if lineRatio <= 0 {
+		return false
+	}
+	lines, short := 0, 0
+	for _, line := range strings.Split(p.Extract, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "==") {
+			continue
+		}
+		lines++
+		if len(strings.Fields(line)) <= 5 {
+			short++
+		}
+	}
+	return lines >= 10 && float64(short)/float64(lines) > lineRatio
+}
+
+// Stats counts dropped pages per reason
+type Stats map[string]int
+
+// String formats the counts in a stable order for the run summary
+func (s Stats) String() string {
+	if len(s) == 0 {
+		return "none"
+	}
+	reasons := make([]string, 0, len(s))
+	for reason := range s {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s=%d", reason, s[reason])
+	}
+	return strings.Join(parts, ", ")
+}
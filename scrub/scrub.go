@@ -0,0 +1,121 @@
+// Package scrub replaces email addresses, phone numbers and URLs in text with
+// placeholder tokens, so that they do not end up in the training data once
+// digits and punctuation are kept. Phone numbers are recognized in ASCII and
+// Bangla digits, with Bangladeshi mobile and landline formats in mind.
+package scrub
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Placeholder tokens
+const (
+	EmailToken = "<EMAIL>"
+	PhoneToken = "<PHONE>"
+	URLToken   = "<URL>"
+)
+
+// Tokens lists the placeholders, for tokenizers that should keep them whole
+var Tokens = []string{EmailToken, PhoneToken, URLToken}
+
+// Names of the patterns, as reported in Counts
+const (
+	KindEmail = "email"
+	KindPhone = "phone"
+	KindURL   = "url"
+)
+
+// digit matches an ASCII or Bangla digit, sep an optional separator
+const (
+	digit = `[0-9০-৯]`
+	sep   = `[-\s.]?`
+)
+
+var patterns = []struct {
+	kind, token string
+	re          *regexp.Regexp
+}{
+	// URLs first, they may contain @ and long digit runs
+	{KindURL, URLToken, regexp.MustCompile(`(?i)(?:https?://|ftp://|www\.)[^\s<>"'()\[\]{}]+`)},
+	{KindEmail, EmailToken, regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}`)},
+	// Bangladeshi mobile: +880 1712-345678, 01712345678, ০১৭১২-৩৪৫৬৭৮
+	{KindPhone, PhoneToken, regexp.MustCompile(`(?:(?:\+|00|০০)?(?:880|৮৮০)` + sep + `|[0০])[1১][3-9৩-৯]` + digit + `{2}` + sep + digit + `{3}` + sep + digit + `{3}`)},
+	// Bangladeshi landline with area code: 02-9551234, +880 2 9551234, ০২-৯৫৫১২৩৪
+	{KindPhone, PhoneToken, regexp.MustCompile(`(?:(?:\+|00|০০)?(?:880|৮৮০)` + sep + `|[0০])[2-9২-৯]` + digit + `?[-\s]` + digit + `{6,8}`)},
+	// Other international numbers, which need the leading +
+	{KindPhone, PhoneToken, regexp.MustCompile(`\+` + digit + `{1,3}` + `(?:[-\s]?` + digit + `){7,12}`)},
+}
+
+// trailing punctuation is left out of URLs and emails, it usually ends the
+// sentence rather than the address
+const trailing = ".,;:!?।"
+
+// Counts counts the replacements per kind
+type Counts map[string]int
+
+// String formats the counts in a stable order for the run report
+func (c Counts) String() string {
+	if len(c) == 0 {
+		return "none"
+	}
+	kinds := make([]string, 0, len(c))
+	for kind := range c {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, len(kinds))
+	for i, kind := range kinds {
+		parts[i] = fmt.Sprintf("%s=%d", kind, c[kind])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Scrub returns text with emails, phone numbers and URLs replaced by their
+// placeholder tokens. If counts is not nil, the replacements are counted.
+func Scrub(text string, counts Counts) string {
+	for _, p := range patterns {
+		text = replace(text, p.re, p.token, func() {
+			if counts != nil {
+				counts[p.kind]++
+			}
+		})
+	}
+	return text
+}
+
+// replace substitutes token for every match of re that is not glued to a
+// longer run of letters or digits
+func replace(text string, re *regexp.Regexp, token string, count func()) string {
+	matches := re.FindAllStringIndex(text, -1)
+	if matches == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if token != PhoneToken {
+			end = start + len(strings.TrimRight(text[start:end], trailing))
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if start < last || isWordChar(before) || (token == PhoneToken && isWordChar(after)) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(token)
+		last = end
+		count()
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func isWordChar(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
@@ -0,0 +1,91 @@
+// Package sections splits TextExtracts plaintext (requested with
+// exsectionformat=wiki) into titled sections and drops unwanted ones.
+package sections
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultBlocklist holds the headings of sections that are not running prose
+var DefaultBlocklist = []string{
+	"তথ্যসূত্র",
+	"বহিঃসংযোগ",
+	"বহিঃসংযোগসমূহ",
+	"আরও দেখুন",
+	"আরো দেখুন",
+	"টীকা",
+	"পাদটীকা",
+	"গ্রন্থপঞ্জি",
+	"উৎস",
+	"References",
+	"External links",
+	"See also",
+	"Notes",
+	"Further reading",
+}
+
+// Section is a heading and the text beneath it. The lead section has level 0
+// and an empty title.
+type Section struct {
+	Level int    `json:"level"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+var headingRegex = regexp.MustCompile(`^(={2,6})\s*(.*?)\s*={2,6}$`)
+
+// Parse splits an extract into sections at "== Heading ==" lines
+func Parse(extract string) []Section {
+	current := Section{}
+	var body []string
+	var result []Section
+
+	flush := func() {
+		current.Text = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.Title != "" || current.Text != "" {
+			result = append(result, current)
+		}
+		body = body[:0]
+	}
+
+	for _, line := range strings.Split(extract, "\n") {
+		if m := headingRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			flush()
+			current = Section{Level: len(m[1]) - 1, Title: m[2]}
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return result
+}
+
+// Drop removes sections whose title is in the blocklist, together with all of
+// their subsections
+func Drop(secs []Section, blocklist []string) []Section {
+	blocked := make(map[string]bool, len(blocklist))
+	for _, title := range blocklist {
+		blocked[normalizeTitle(title)] = true
+	}
+
+	var kept []Section
+	dropLevel := 0
+	for _, s := range secs {
+		if dropLevel > 0 && s.Level > dropLevel {
+			continue
+		}
+		dropLevel = 0
+		if s.Level > 0 && blocked[normalizeTitle(s.Title)] {
+			dropLevel = s.Level
+			continue
+		}
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
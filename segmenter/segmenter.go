@@ -0,0 +1,210 @@
+// Package segmenter splits Bangla text into sentences. It ends sentences at
+// danda (।), double danda (॥ or ।।), question and exclamation marks, and at
+// ASCII periods that are not part of an abbreviation, initial or number.
+package segmenter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BanglaAbbreviations are words that are written with a trailing period
+var BanglaAbbreviations = []string{
+	// Bangla titles and honorifics
+	"ড", "ডা", "মো", "মোসা", "মোছা", "মোহা", "মু", "মুহা", "প্রফ", "প্রো", "মি",
+	// Religious honorifics
+	"আ", "রা", "সা", "রহ",
+	// Common short forms
+	"খ্রি", "খ্রিস্টপূর্ব", "ইং", "লি", "প্রা", "দ্র", "পৃ", "নং", "সং", "অনু", "কো", "বি",
+	// English
+	"dr", "mr", "mrs", "ms", "prof", "st", "jr", "sr", "co", "ltd", "inc", "vs", "etc", "no", "e.g", "i.e",
+}
+
+// Segmenter splits text into sentences
+type Segmenter struct {
+	terminators   map[rune]bool
+	abbreviations map[string]bool
+}
+
+// New creates a Segmenter with the given hard terminators and abbreviations
+// that may be followed by a period inside a sentence
+func New(terminators []rune, abbreviations []string) *Segmenter {
+	s := &Segmenter{
+		terminators:   make(map[rune]bool, len(terminators)),
+		abbreviations: make(map[string]bool, len(abbreviations)),
+	}
+	for _, r := range terminators {
+		s.terminators[r] = true
+	}
+	for _, a := range abbreviations {
+		s.abbreviations[strings.ToLower(a)] = true
+	}
+	return s
+}
+
+var bangla = New([]rune{'।', '॥', '?', '!'}, BanglaAbbreviations)
+
+// Split splits Bangla text into sentences using the default rules
+func Split(text string) []string {
+	return bangla.Split(text)
+}
+
+// Spans returns the byte ranges of the sentences Split would return
+func Spans(text string) []Span {
+	return bangla.Spans(text)
+}
+
+// Span is the byte range of a trimmed sentence in the text it came from
+type Span struct {
+	Start, End int
+}
+
+// Split returns the trimmed, non-empty sentences of text. Newlines always end
+// a sentence, since extracts put headings and list items on their own lines.
+func (s *Segmenter) Split(text string) []string {
+	var sentences []string
+	for _, span := range s.Spans(text) {
+		sentences = append(sentences, text[span.Start:span.End])
+	}
+	return sentences
+}
+
+// Spans returns the byte ranges of the sentences of text, as Split cuts them
+func (s *Segmenter) Spans(text string) []Span {
+	runes := []rune(text)
+	offsets := make([]int, 0, len(runes)+1) // Byte offset of each rune
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	offsets = append(offsets, len(text))
+	var spans []Span
+	start := 0
+
+	emit := func(end int) {
+		from, to := offsets[start], offsets[end]
+		sentence := text[from:to]
+		trimmed := strings.TrimLeftFunc(sentence, unicode.IsSpace)
+		from += len(sentence) - len(trimmed)
+		to = from + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+		if to > from {
+			spans = append(spans, Span{from, to})
+		}
+		start = end
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '\n' {
+			emit(i)
+			continue
+		}
+		if !s.terminators[r] && !(r == '.' && s.periodEnds(runes, i)) {
+			continue
+		}
+
+		// Absorb repeated terminators ("।।", "?!") and closing quotes
+		j := i + 1
+		for j < len(runes) && (s.terminators[runes[j]] || runes[j] == '.') {
+			j++
+		}
+		for j < len(runes) && isClosing(runes[j]) {
+			j++
+		}
+		emit(j)
+		i = j - 1
+	}
+	emit(len(runes))
+
+	return spans
+}
+
+// Replace rebuilds text with every sentence replaced by fn(i, sentence),
+// keeping the text between sentences as it was. A sentence replaced by ""
+// is cut out together with the spaces after it, or before it when it ends
+// its line, so that line breaks survive.
+func Replace(text string, spans []Span, fn func(i int, sentence string) string) string {
+	var b strings.Builder
+	last := 0
+	for i, span := range spans {
+		sentence := text[span.Start:span.End]
+		replacement := fn(i, sentence)
+		if replacement != "" {
+			b.WriteString(text[last:span.Start])
+			b.WriteString(replacement)
+			last = span.End
+			continue
+		}
+		rest := strings.TrimLeft(text[span.End:], " \t")
+		if rest == "" || rest[0] == '\n' || rest[0] == '\r' {
+			// Last sentence of its line: drop the spaces before it instead
+			b.WriteString(strings.TrimRight(text[last:span.Start], " \t"))
+			last = span.End
+		} else {
+			b.WriteString(text[last:span.Start])
+			last = len(text) - len(rest)
+		}
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// periodEnds reports whether the period at runes[i] ends a sentence
+func (s *Segmenter) periodEnds(runes []rune, i int) bool {
+	// Ellipses and numbers like 3.5 or ৩.৫
+	if i > 0 && runes[i-1] == '.' {
+		return false
+	}
+	if i+1 < len(runes) {
+		next := runes[i+1]
+		if next == '.' {
+			return false
+		}
+		// URLs, e-mails and dotted initials without spaces ("A.K.")
+		if !unicode.IsSpace(next) && !isClosing(next) {
+			return false
+		}
+	}
+
+	// The word before the period
+	begin := i
+	for begin > 0 && !unicode.IsSpace(runes[begin-1]) && !isOpening(runes[begin-1]) {
+		begin--
+	}
+	word := runes[begin:i]
+	if len(word) == 0 {
+		return true
+	}
+	if s.abbreviations[strings.ToLower(string(word))] {
+		return false
+	}
+	return !isInitial(word)
+}
+
+// isInitial matches a single letter with optional signs, as in "এ. কে. ফজলুল"
+func isInitial(word []rune) bool {
+	if !unicode.IsLetter(word[0]) {
+		return false
+	}
+	for _, r := range word[1:] {
+		if !unicode.IsMark(r) {
+			return false
+		}
+	}
+	return len(word) <= 3
+}
+
+func isClosing(r rune) bool {
+	switch r {
+	case '"', '\'', '”', '’', '»', ')', ']', '}':
+		return true
+	}
+	return false
+}
+
+func isOpening(r rune) bool {
+	switch r {
+	case '"', '\'', '“', '‘', '«', '(', '[', '{':
+		return true
+	}
+	return false
+}
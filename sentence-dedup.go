@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/segmenter"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/sketch"
+)
+
+type SentenceCount struct {
+	sentence string
+	count    int
+}
+
+func main() {
+	inputFile := flag.String("input", "", "Corpus file, one document or sentence per line")
+	outputFile := flag.String("output", "", "Output file without the repeated sentences")
+	reportFile := flag.String("report", "", "Optional report of the repeated sentences and their counts")
+	maxRepeats := flag.Int("max-repeats", 100, "Sentences seen more than this many times are treated as boilerplate")
+	mode := flag.String("mode", "remove", "What to do with boilerplate sentences: remove or downsample")
+	keep := flag.Int("keep", 10, "Occurrences to keep of each boilerplate sentence in downsample mode")
+	memoryMB := flag.Int("memory", 256, "Memory budget in MB for the frequency sketch")
+	flag.Parse()
+
+	if *inputFile == "" || *outputFile == "" {
+		fmt.Println("Usage: go run sentence-dedup.go --input=merged.txt --output=merged.dedup.txt [--max-repeats=100] [--mode=remove|downsample]")
+		os.Exit(1)
+	}
+	if *mode != "remove" && *mode != "downsample" {
+		fmt.Printf("Unknown mode %q, expected remove or downsample\n", *mode)
+		os.Exit(1)
+	}
+	if *mode == "remove" {
+		*keep = 0
+	}
+
+	// Pass 1: approximate sentence frequencies in bounded memory
+	startTime := time.Now()
+	cms, err := sketch.NewCountMinWithMemory(4, *memoryMB)
+	if err != nil {
+		fmt.Printf("Error creating sketch: %v\n", err)
+		os.Exit(1)
+	}
+	err = forEachLine(*inputFile, func(line string) error {
+		for _, s := range segmenter.Split(line) {
+			cms.Add(sentenceKey(s), 1)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error reading input file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Pass 1: %d sentences sketched in %v\n", cms.Total(), time.Since(startTime).Round(time.Second))
+
+	// Pass 2: exact counts, but only for sentences the sketch flags as frequent.
+	// The sketch never undercounts, so no boilerplate sentence is missed.
+	startTime = time.Now()
+	candidates := make(map[uint64]int)
+	examples := make(map[uint64]string)
+	err = forEachLine(*inputFile, func(line string) error {
+		for _, s := range segmenter.Split(line) {
+			key := sentenceKey(s)
+			if cms.Estimate(key) > uint32(*maxRepeats) {
+				if _, ok := candidates[key]; !ok {
+					examples[key] = s
+				}
+				candidates[key]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error reading input file: %v\n", err)
+		os.Exit(1)
+	}
+	boilerplate := make(map[uint64]int)
+	for key, count := range candidates {
+		if count > *maxRepeats {
+			boilerplate[key] = count
+		}
+	}
+	fmt.Printf("Pass 2: %d candidates, %d boilerplate sentences in %v\n",
+		len(candidates), len(boilerplate), time.Since(startTime).Round(time.Second))
+
+	// Pass 3: rewrite the corpus, keeping evenly spaced occurrences when downsampling
+	startTime = time.Now()
+	outputHandle, err := os.Create(*outputFile)
+	if err != nil {
+		fmt.Printf("Error creating output file: %v\n", err)
+		os.Exit(1)
+	}
+	defer outputHandle.Close()
+	writer := bufio.NewWriter(outputHandle)
+
+	seen := make(map[uint64]int)
+	removed := 0
+	err = forEachLine(*inputFile, func(line string) error {
+		// Removed sentences are cut out, the rest of the line stays as it was
+		spans := segmenter.Spans(line)
+		dropped := 0
+		rewritten := segmenter.Replace(line, spans, func(_ int, s string) string {
+			key := sentenceKey(s)
+			if total, ok := boilerplate[key]; ok {
+				i := seen[key]
+				seen[key]++
+				if i*(*keep)/total == (i+1)*(*keep)/total {
+					removed++
+					dropped++
+					return ""
+				}
+			}
+			return s
+		})
+		if len(spans) > 0 && dropped == len(spans) {
+			return nil // Only boilerplate, blank lines of the input are kept
+		}
+		_, err := writer.WriteString(rewritten + "\n")
+		return err
+	})
+	if err != nil {
+		fmt.Printf("Error rewriting corpus: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Printf("Error writing to output file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Pass 3: removed %d sentence occurrences in %v\n", removed, time.Since(startTime).Round(time.Second))
+
+	if *reportFile != "" {
+		var counts []SentenceCount
+		for key, count := range boilerplate {
+			counts = append(counts, SentenceCount{examples[key], count})
+		}
+		sort.Slice(counts, func(i, j int) bool {
+			if counts[i].count != counts[j].count {
+				return counts[i].count > counts[j].count
+			}
+			return counts[i].sentence < counts[j].sentence
+		})
+		if err := writeReport(*reportFile, counts); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// sentenceKey hashes a sentence with its whitespace normalized
+func sentenceKey(sentence string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(strings.Fields(sentence), " ")))
+	return h.Sum64()
+}
+
+// forEachLine streams a file line by line without a line-length limit
+func forEachLine(path string, fn func(line string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if err := fn(strings.TrimRight(line, "\r\n")); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeReport(path string, counts []SentenceCount) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, c := range counts {
+		if _, err := fmt.Fprintf(w, "%d\t%s\n", c.count, c.sentence); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+// Package sketch provides fixed-memory frequency estimators for streams that
+// are too large to count exactly.
+package sketch
+
+import (
+	"fmt"
+	"math"
+)
+
+// CountMin is a Count-Min Sketch over 64-bit keys. Estimates never undercount;
+// they overcount by at most Epsilon()*Total() with probability 1-Delta().
+type CountMin struct {
+	width  uint64
+	depth  int
+	counts []uint32
+	seeds  []uint64
+	total  uint64
+}
+
+// NewCountMin creates a sketch with the given number of rows and columns
+func NewCountMin(depth, width int) (*CountMin, error) {
+	if depth < 1 || width < 1 {
+		return nil, fmt.Errorf("invalid sketch size %dx%d", depth, width)
+	}
+	seeds := make([]uint64, depth)
+	for i := range seeds {
+		seeds[i] = Mix64(uint64(i+1) * 0x9E3779B97F4A7C15)
+	}
+	return &CountMin{
+		width:  uint64(width),
+		depth:  depth,
+		counts: make([]uint32, depth*width),
+		seeds:  seeds,
+	}, nil
+}
+
+// NewCountMinWithMemory sizes a sketch of the given depth to fit in memoryMB
+func NewCountMinWithMemory(depth int, memoryMB int) (*CountMin, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("invalid sketch depth %d", depth)
+	}
+	width := memoryMB * 1024 * 1024 / 4 / depth
+	return NewCountMin(depth, width)
+}
+
+// Add increments the count of key by n
+func (c *CountMin) Add(key uint64, n uint32) {
+	c.total += uint64(n)
+	for i, seed := range c.seeds {
+		idx := uint64(i)*c.width + Mix64(key^seed)%c.width
+		if c.counts[idx] > math.MaxUint32-n {
+			c.counts[idx] = math.MaxUint32
+		} else {
+			c.counts[idx] += n
+		}
+	}
+}
+
+// Estimate returns an upper bound on the count of key
+func (c *CountMin) Estimate(key uint64) uint32 {
+	est := uint32(math.MaxUint32)
+	for i, seed := range c.seeds {
+		idx := uint64(i)*c.width + Mix64(key^seed)%c.width
+		if c.counts[idx] < est {
+			est = c.counts[idx]
+		}
+	}
+	return est
+}
+
+// Total returns the sum of all counts added
+func (c *CountMin) Total() uint64 {
+	return c.total
+}
+
+// Epsilon is the relative overcount bound e/width
+func (c *CountMin) Epsilon() float64 {
+	return math.E / float64(c.width)
+}
+
+// Delta is the probability that an estimate exceeds the Epsilon bound
+func (c *CountMin) Delta() float64 {
+	return math.Exp(-float64(c.depth))
+}
+
+// Mix64 is the splitmix64 finalizer, used to derive independent hashes
+func Mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
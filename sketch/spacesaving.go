@@ -0,0 +1,166 @@
+package sketch
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SpaceSaving finds the most frequent keys of a stream with a fixed number of
+// counters (Metwally et al.). Every monitored key's count overestimates its
+// true count by at most its Error, and a key that is not monitored occurs at
+// most Floor() times, which is never more than Total()/capacity.
+type SpaceSaving struct {
+	capacity int
+	items    map[string]*ssItem
+	heap     ssHeap
+	total    uint64
+}
+
+// Item is a monitored key. Its true count lies in [Count-Error, Count].
+type Item struct {
+	Key        string
+	Count      uint64
+	Error      uint64
+	Guaranteed bool // Set by Top when the key is certainly among the top keys
+}
+
+type ssItem struct {
+	key   string
+	count uint64
+	err   uint64
+	index int
+}
+
+// NewSpaceSaving creates a summary with the given number of counters
+func NewSpaceSaving(capacity int) (*SpaceSaving, error) {
+	if capacity < 1 {
+		return nil, fmt.Errorf("invalid number of counters %d", capacity)
+	}
+	return &SpaceSaving{capacity: capacity, items: make(map[string]*ssItem, capacity)}, nil
+}
+
+// Add counts one occurrence of key. New keys are copied.
+func (s *SpaceSaving) Add(key string) {
+	s.total++
+	if it := s.items[key]; it != nil {
+		it.count++
+		heap.Fix(&s.heap, it.index)
+		return
+	}
+	if len(s.items) < s.capacity {
+		it := &ssItem{key: strings.Clone(key), count: 1}
+		s.items[it.key] = it
+		heap.Push(&s.heap, it)
+		return
+	}
+	// Replace the least frequent key; the new one may have been counted there
+	it := s.heap[0]
+	delete(s.items, it.key)
+	it.key = strings.Clone(key)
+	it.err = it.count
+	it.count++
+	s.items[it.key] = it
+	heap.Fix(&s.heap, 0)
+}
+
+// Total returns the number of occurrences added
+func (s *SpaceSaving) Total() uint64 {
+	return s.total
+}
+
+// Floor is the most a key that is not monitored can have occurred
+func (s *SpaceSaving) Floor() uint64 {
+	if len(s.items) < s.capacity {
+		return 0
+	}
+	return s.heap[0].count
+}
+
+// Merge adds the counts of another summary (Agarwal et al., Mergeable
+// Summaries). A key missing from one summary is counted with that summary's
+// Floor, which also goes into its Error.
+func (s *SpaceSaving) Merge(o *SpaceSaving) {
+	sFloor, oFloor := s.Floor(), o.Floor()
+	merged := make([]*ssItem, 0, len(s.items)+len(o.items))
+	for key, it := range s.items {
+		if ot := o.items[key]; ot != nil {
+			it.count += ot.count
+			it.err += ot.err
+		} else {
+			it.count += oFloor
+			it.err += oFloor
+		}
+		merged = append(merged, it)
+	}
+	for key, ot := range o.items {
+		if s.items[key] == nil {
+			merged = append(merged, &ssItem{key: key, count: ot.count + sFloor, err: ot.err + sFloor})
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].count != merged[j].count {
+			return merged[i].count > merged[j].count
+		}
+		return merged[i].key < merged[j].key
+	})
+	if len(merged) > s.capacity {
+		merged = merged[:s.capacity]
+	}
+
+	s.items = make(map[string]*ssItem, s.capacity)
+	s.heap = s.heap[:0]
+	for _, it := range merged {
+		s.items[it.key] = it
+		heap.Push(&s.heap, it)
+	}
+	s.total += o.total
+}
+
+// Top returns the n most frequent monitored keys, by count and then key. A
+// key is Guaranteed to be among the true top n if its lower bound is at least
+// the count of every key ranked below it.
+func (s *SpaceSaving) Top(n int) []Item {
+	items := make([]Item, 0, len(s.items))
+	for _, it := range s.items {
+		items = append(items, Item{Key: it.key, Count: it.count, Error: it.err})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+	threshold := s.Floor()
+	if n < len(items) {
+		threshold = max(threshold, items[n].Count)
+		items = items[:n]
+	}
+	for i := range items {
+		items[i].Guaranteed = items[i].Count-items[i].Error >= threshold
+	}
+	return items
+}
+
+// ssHeap keeps the least frequent monitored key on top
+type ssHeap []*ssItem
+
+func (h ssHeap) Len() int           { return len(h) }
+func (h ssHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h ssHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *ssHeap) Push(x interface{}) {
+	it := x.(*ssItem)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+func (h *ssHeap) Pop() interface{} {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,320 @@
+package stats
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// WriteHTML writes a self-contained HTML report, with no scripts or external
+// files. When previous is not nil, e.g. the report of the last corpus
+// version, its numbers and curves are shown next to the current ones.
+func WriteHTML(w io.Writer, report, previous *Report) error {
+	data := struct {
+		Report, Previous *Report
+		Summary          []summaryRow
+		Scripts          []scriptRow
+		Zipf, Heaps      template.HTML
+		Charts           []chart
+	}{
+		Report:   report,
+		Previous: previous,
+		Summary:  summary(report, previous),
+		Scripts:  scripts(report, previous),
+		Zipf:     lineChart(report.Zipf, previousCurve(previous, func(r *Report) []Point { return r.Zipf }), true, "rank", "count"),
+		Heaps:    lineChart(report.Heaps, previousCurve(previous, func(r *Report) []Point { return r.Heaps }), false, "tokens", "types"),
+	}
+	for _, h := range []struct {
+		title string
+		get   func(r *Report) Histogram
+	}{
+		{"Word length (grapheme clusters, by token)", func(r *Report) Histogram { return r.WordLength }},
+		{"Sentence length (words)", func(r *Report) Histogram { return r.SentenceLength }},
+		{"Document length (words)", func(r *Report) Histogram { return r.DocumentLength }},
+	} {
+		var prev *Histogram
+		if previous != nil {
+			p := h.get(previous)
+			prev = &p
+		}
+		data.Charts = append(data.Charts, chart{h.title, barChart(h.get(report), prev)})
+	}
+	return reportTemplate.Execute(w, data)
+}
+
+type summaryRow struct {
+	Name, Value, Previous, Change string
+}
+
+type scriptRow struct {
+	Name            string
+	Share, Previous string
+	Width           float64
+}
+
+type chart struct {
+	Title string
+	SVG   template.HTML
+}
+
+func summary(r, previous *Report) []summaryRow {
+	metrics := []struct {
+		name   string
+		get    func(r *Report) float64
+		format string
+	}{
+		{"Bytes", func(r *Report) float64 { return float64(r.Bytes) }, "%.0f"},
+		{"Documents", func(r *Report) float64 { return float64(r.Documents) }, "%.0f"},
+		{"Sentences", func(r *Report) float64 { return float64(r.Sentences) }, "%.0f"},
+		{"Tokens", func(r *Report) float64 { return float64(r.Tokens) }, "%.0f"},
+		{"Types", func(r *Report) float64 { return float64(r.Types) }, "%.0f"},
+		{"Hapax legomena", func(r *Report) float64 { return float64(r.Hapax) }, "%.0f"},
+		{"Dis legomena", func(r *Report) float64 { return float64(r.DisLegomena) }, "%.0f"},
+		{"Type/token ratio", func(r *Report) float64 { return r.TypeTokenRatio }, "%.5f"},
+		{"Zipf slope", func(r *Report) float64 { return r.ZipfSlope }, "%.3f"},
+		{"Zipf fit R²", func(r *Report) float64 { return r.ZipfR2 }, "%.3f"},
+		{"Heaps K", func(r *Report) float64 { return r.HeapsK }, "%.2f"},
+		{"Heaps β", func(r *Report) float64 { return r.HeapsBeta }, "%.3f"},
+		{"Mean word length", func(r *Report) float64 { return r.WordLength.Mean }, "%.2f"},
+		{"Mean sentence length", func(r *Report) float64 { return r.SentenceLength.Mean }, "%.2f"},
+		{"Median sentence length", func(r *Report) float64 { return float64(r.SentenceLength.Median) }, "%.0f"},
+		{"Mean document length", func(r *Report) float64 { return r.DocumentLength.Mean }, "%.1f"},
+		{"Median document length", func(r *Report) float64 { return float64(r.DocumentLength.Median) }, "%.0f"},
+	}
+	rows := make([]summaryRow, len(metrics))
+	for i, m := range metrics {
+		v := m.get(r)
+		rows[i] = summaryRow{Name: m.name, Value: fmt.Sprintf(m.format, v)}
+		if previous != nil {
+			p := m.get(previous)
+			rows[i].Previous = fmt.Sprintf(m.format, p)
+			if p != 0 {
+				rows[i].Change = fmt.Sprintf("%+.1f%%", 100*(v-p)/math.Abs(p))
+			}
+		}
+	}
+	return rows
+}
+
+func scripts(r, previous *Report) []scriptRow {
+	share := func(r *Report, name string) float64 {
+		var total int64
+		for _, n := range r.Scripts {
+			total += n
+		}
+		if total == 0 {
+			return 0
+		}
+		return 100 * float64(r.Scripts[name]) / float64(total)
+	}
+	names := make([]string, 0, len(r.Scripts))
+	for name := range r.Scripts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return r.Scripts[names[i]] > r.Scripts[names[j]] })
+	rows := make([]scriptRow, len(names))
+	for i, name := range names {
+		s := share(r, name)
+		rows[i] = scriptRow{Name: name, Share: fmt.Sprintf("%.2f%%", s), Width: 3 * s}
+		if previous != nil {
+			rows[i].Previous = fmt.Sprintf("%.2f%%", share(previous, name))
+		}
+	}
+	return rows
+}
+
+func previousCurve(previous *Report, get func(r *Report) []Point) []Point {
+	if previous == nil {
+		return nil
+	}
+	return get(previous)
+}
+
+// Chart geometry
+const (
+	chartWidth  = 520
+	chartHeight = 280
+	margin      = 56
+)
+
+// lineChart draws the current curve and, in gray, the previous one
+func lineChart(current, previous []Point, logScale bool, xLabel, yLabel string) template.HTML {
+	scale := func(v int64) float64 {
+		if logScale {
+			return math.Log10(float64(max(v, 1)))
+		}
+		return float64(v)
+	}
+	minX, maxX, minY, maxY := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, p := range append(append([]Point(nil), current...), previous...) {
+		minX, maxX = math.Min(minX, scale(p.X)), math.Max(maxX, scale(p.X))
+		minY, maxY = math.Min(minY, scale(p.Y)), math.Max(maxY, scale(p.Y))
+	}
+	if !logScale {
+		minX, minY = 0, 0
+	}
+	if math.IsInf(minX, 0) || maxX == minX || maxY == minY {
+		return template.HTML(`<p class="empty">Not enough data</p>`)
+	}
+	x := func(v int64) float64 {
+		return margin + (scale(v)-minX)/(maxX-minX)*(chartWidth-2*margin)
+	}
+	y := func(v int64) float64 {
+		return chartHeight - margin - (scale(v)-minY)/(maxY-minY)*(chartHeight-2*margin)
+	}
+	axisValue := func(v float64) string {
+		if logScale {
+			v = math.Pow(10, v)
+		}
+		return fmt.Sprintf("%.0f", v)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" width="%d" height="%d">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<path class="axis" d="M%d %d V%d H%d"/>`, margin, margin/2, chartHeight-margin, chartWidth-margin/2)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text>`, margin, chartHeight-margin+16, axisValue(minX))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, chartWidth-margin, chartHeight-margin+16, axisValue(maxX))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle">%s%s</text>`, chartWidth/2, chartHeight-margin+32, xLabel, logLabel(logScale))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, margin-4, chartHeight-margin, axisValue(minY))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, margin-4, margin+4, axisValue(maxY))
+	fmt.Fprintf(&b, `<text x="%d" y="%d">%s%s</text>`, margin+4, margin/2+4, yLabel, logLabel(logScale))
+	for _, s := range []struct {
+		points []Point
+		class  string
+	}{{previous, "previous"}, {current, "current"}} {
+		if len(s.points) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `<polyline class="%s" points="`, s.class)
+		for _, p := range s.points {
+			fmt.Fprintf(&b, "%.1f,%.1f ", x(p.X), y(p.Y))
+		}
+		b.WriteString(`"/>`)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func logLabel(logScale bool) string {
+	if logScale {
+		return " (log)"
+	}
+	return ""
+}
+
+// barChart draws the share of each bin, with the previous shares as thin
+// gray bars
+func barChart(current Histogram, previous *Histogram) template.HTML {
+	shares := func(h Histogram) []float64 {
+		var total int64
+		for _, bin := range h.Bins {
+			total += bin.Count
+		}
+		s := make([]float64, len(h.Bins))
+		for i, bin := range h.Bins {
+			if total > 0 {
+				s[i] = float64(bin.Count) / float64(total)
+			}
+		}
+		return s
+	}
+	cur := shares(current)
+	var prev []float64
+	if previous != nil {
+		prev = shares(*previous)
+	}
+	n := max(len(cur), len(prev))
+	if n == 0 {
+		return template.HTML(`<p class="empty">No data</p>`)
+	}
+	top := 0.0
+	for _, s := range append(append([]float64(nil), cur...), prev...) {
+		top = math.Max(top, s)
+	}
+	slot := float64(chartWidth-2*margin) / float64(n)
+	height := func(s float64) float64 { return s / top * (chartHeight - 2*margin) }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" width="%d" height="%d">`, chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<path class="axis" d="M%d %d V%d H%d"/>`, margin, margin/2, chartHeight-margin, chartWidth-margin/2)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%.1f%%</text>`, margin-4, margin+4, 100*top)
+	for i := 0; i < n; i++ {
+		left := margin + float64(i)*slot
+		if i < len(cur) {
+			h := height(cur[i])
+			fmt.Fprintf(&b, `<rect class="current" x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%s: %.2f%%</title></rect>`,
+				left+1, chartHeight-margin-h, slot*0.7, h, binLabel(current.Bins[i]), 100*cur[i])
+			fmt.Fprintf(&b, `<text class="tick" x="%.1f" y="%d" text-anchor="middle">%d</text>`, left+slot/2, chartHeight-margin+14, current.Bins[i].From)
+		}
+		if i < len(prev) {
+			h := height(prev[i])
+			fmt.Fprintf(&b, `<rect class="previous" x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>previous %s: %.2f%%</title></rect>`,
+				left+slot*0.7, chartHeight-margin-h, slot*0.25, h, binLabel(previous.Bins[i]), 100*prev[i])
+		}
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+func binLabel(bin Bin) string {
+	if bin.From == bin.To {
+		return fmt.Sprint(bin.From)
+	}
+	return fmt.Sprintf("%d-%d", bin.From, bin.To)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Corpus statistics: {{.Report.Corpus}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { padding: 0.25em 0.75em; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.charts { display: flex; flex-wrap: wrap; gap: 2em; }
+svg text { font-size: 11px; fill: #444; }
+svg .tick { font-size: 9px; }
+.axis { stroke: #888; fill: none; }
+polyline.current { stroke: #1f6fb2; stroke-width: 2; fill: none; }
+polyline.previous { stroke: #aaa; stroke-width: 2; fill: none; }
+rect.current { fill: #1f6fb2; }
+rect.previous { fill: #bbb; }
+.bar { background: #1f6fb2; height: 0.8em; display: inline-block; }
+.empty { color: #888; }
+</style>
+</head>
+<body>
+<h1>Corpus statistics</h1>
+<p>{{.Report.Corpus}}{{if .Previous}}, compared with {{.Previous.Corpus}} (gray){{end}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th></th><th>Value</th>{{if .Previous}}<th>Previous</th><th>Change</th>{{end}}</tr>
+{{range .Summary}}<tr><td>{{.Name}}</td><td>{{.Value}}</td>{{if $.Previous}}<td>{{.Previous}}</td><td>{{.Change}}</td>{{end}}</tr>
+{{end}}</table>
+
+<div class="charts">
+<div><h2>Zipf</h2>{{.Zipf}}</div>
+<div><h2>Heaps' law</h2>{{.Heaps}}</div>
+{{range .Charts}}<div><h2>{{.Title}}</h2>{{.SVG}}</div>
+{{end}}</div>
+
+<h2>Script composition (characters)</h2>
+<table>
+<tr><th></th><th>Share</th>{{if .Previous}}<th>Previous</th>{{end}}<th></th></tr>
+{{range .Scripts}}<tr><td>{{.Name}}</td><td>{{.Share}}</td>{{if $.Previous}}<td>{{.Previous}}</td>{{end}}<td style="text-align:left"><span class="bar" style="width: {{printf "%.1f" .Width}}px"></span></td></tr>
+{{end}}</table>
+
+<h2>Most frequent words</h2>
+<table>
+<tr><th>Word</th><th>Count</th></tr>
+{{range .Report.TopWords}}<tr><td>{{.Word}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,407 @@
+// Package stats computes corpus statistics in one parallel pass over a
+// mapped corpus file: token and type counts, hapax legomena, the Zipf slope,
+// a Heaps' law growth curve, word, sentence and document length histograms
+// and the script composition of the text. Documents are lines, as written by
+// the downloader and the cleaner.
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+// Options controls a Compute
+type Options struct {
+	Workers     int
+	Tokenize    wordcount.Tokenize
+	Sentences   func(text string) []string
+	Script      *unicode.RangeTable // Script of the language, for the composition
+	ScriptName  string
+	HeapsPoints int               // Points of the Heaps' curve, 100 when 0
+	TopWords    int               // Most frequent words to list, 20 when 0
+	Progress    func(bytes int64) // Called after each chunk when not nil; must be safe for concurrent use
+}
+
+// Report holds the statistics of a corpus
+type Report struct {
+	Corpus         string                `json:"corpus"`
+	Bytes          int64                 `json:"bytes"`
+	Documents      int64                 `json:"documents"`
+	Sentences      int64                 `json:"sentences"`
+	Tokens         int64                 `json:"tokens"`
+	Types          int                   `json:"types"`
+	Hapax          int                   `json:"hapax_legomena"`
+	DisLegomena    int                   `json:"dis_legomena"`
+	TypeTokenRatio float64               `json:"type_token_ratio"`
+	ZipfSlope      float64               `json:"zipf_slope"`
+	ZipfR2         float64               `json:"zipf_r2"`
+	Zipf           []Point               `json:"zipf"` // Rank and count, at log-spaced ranks
+	HeapsK         float64               `json:"heaps_k"`
+	HeapsBeta      float64               `json:"heaps_beta"`
+	Heaps          []Point               `json:"heaps"` // Tokens read and types seen
+	WordLength     Histogram             `json:"word_length"`
+	SentenceLength Histogram             `json:"sentence_length"`
+	DocumentLength Histogram             `json:"document_length"`
+	Scripts        map[string]int64      `json:"scripts"` // Characters per script or class
+	TopWords       []wordcount.WordCount `json:"top_words"`
+}
+
+// Point is a point of a curve
+type Point struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// Histogram is a length distribution. Word lengths are in grapheme clusters
+// and weighted by tokens, sentence and document lengths are in words.
+type Histogram struct {
+	Bins   []Bin   `json:"bins"`
+	Mean   float64 `json:"mean"`
+	Median int     `json:"median"`
+	Max    int     `json:"max"`
+}
+
+// Bin counts the lengths from From to To, both included
+type Bin struct {
+	From  int   `json:"from"`
+	To    int   `json:"to"`
+	Count int64 `json:"count"`
+}
+
+// Character classes of the script composition
+const (
+	classScript = iota
+	classLatin
+	classDigit
+	classPunct
+	classSpace
+	classOther
+	numClasses
+)
+
+// entry is the count of a word and where it was first seen: the token index
+// in the chunk, chunks being read in file order by each worker
+type entry struct {
+	count int64
+	chunk int
+	pos   int64
+}
+
+// partial holds what one worker has counted
+type partial struct {
+	words           map[string]*entry
+	sentenceLengths map[int]int64
+	documentLengths map[int]int64
+	chars           [numClasses]int64
+	sentences       int64
+}
+
+// Compute reads data once with opts.Workers workers and returns its statistics
+func Compute(data []byte, opts Options) *Report {
+	workers := max(opts.Workers, 1)
+	chunks := wordcount.Chunks(data, wordcount.ChunkSizeFor(len(data), workers))
+	type job struct {
+		index int
+		chunk []byte
+	}
+	jobs := make(chan job, workers)
+	go func() {
+		for i, chunk := range chunks {
+			jobs <- job{i, chunk}
+		}
+		close(jobs)
+	}()
+
+	chunkTokens := make([]int64, len(chunks))
+	partials := make([]*partial, workers)
+	var wg sync.WaitGroup
+	for i := range partials {
+		p := &partial{
+			words:           make(map[string]*entry),
+			sentenceLengths: make(map[int]int64),
+			documentLengths: make(map[int]int64),
+		}
+		partials[i] = p
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				var pos int64
+				wordcount.EachLine(j.chunk, func(line string) {
+					p.document(line, j.index, &pos, opts)
+				})
+				chunkTokens[j.index] = pos
+				if opts.Progress != nil {
+					opts.Progress(int64(len(j.chunk)))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Merge the workers' words, keeping the earliest first sighting
+	words := partials[0].words
+	for _, p := range partials[1:] {
+		for word, e := range p.words {
+			if w := words[word]; w == nil {
+				words[word] = e
+			} else {
+				w.count += e.count
+				if e.chunk < w.chunk || (e.chunk == w.chunk && e.pos < w.pos) {
+					w.chunk, w.pos = e.chunk, e.pos
+				}
+			}
+		}
+		p.words = nil
+	}
+
+	report := &Report{Bytes: int64(len(data)), Types: len(words), Scripts: make(map[string]int64)}
+	offsets := make([]int64, len(chunks))
+	for i, n := range chunkTokens {
+		offsets[i] = report.Tokens
+		report.Tokens += n
+	}
+	sentenceLengths := make(map[int]int64)
+	documentLengths := make(map[int]int64)
+	var chars [numClasses]int64
+	for _, p := range partials {
+		report.Sentences += p.sentences
+		for n, c := range p.sentenceLengths {
+			sentenceLengths[n] += c
+		}
+		for n, c := range p.documentLengths {
+			documentLengths[n] += c
+			report.Documents += c
+		}
+		for class, c := range p.chars {
+			chars[class] += c
+		}
+	}
+	classNames := [numClasses]string{opts.ScriptName, "Latin", "ASCII digits", "punctuation", "whitespace", "other"}
+	for class, c := range chars {
+		if c > 0 {
+			report.Scripts[classNames[class]] += c
+		}
+	}
+
+	// Counts by rank, first sightings in token order and word lengths
+	topN := opts.TopWords
+	if topN <= 0 {
+		topN = 20
+	}
+	top := wordcount.NewTop(topN)
+	counts := make([]int64, 0, len(words))
+	firsts := make([]int64, 0, len(words))
+	wordLengths := make(map[int]int64)
+	for word, e := range words {
+		counts = append(counts, e.count)
+		firsts = append(firsts, offsets[e.chunk]+e.pos)
+		wordLengths[clusterCount(word)] += e.count
+		top.Add(word, int(e.count))
+		switch e.count {
+		case 1:
+			report.Hapax++
+		case 2:
+			report.DisLegomena++
+		}
+	}
+	report.TopWords = top.Sorted()
+	if report.Tokens > 0 {
+		report.TypeTokenRatio = float64(report.Types) / float64(report.Tokens)
+	}
+
+	sort.Slice(counts, func(i, j int) bool { return counts[i] > counts[j] })
+	report.Zipf = zipfPoints(counts)
+	report.ZipfSlope, _, report.ZipfR2 = fitLogLog(report.Zipf)
+
+	sort.Slice(firsts, func(i, j int) bool { return firsts[i] < firsts[j] })
+	heapsPoints := opts.HeapsPoints
+	if heapsPoints <= 0 {
+		heapsPoints = 100
+	}
+	report.Heaps = heapsCurve(firsts, report.Tokens, heapsPoints)
+	var logK float64
+	report.HeapsBeta, logK, _ = fitLogLog(report.Heaps)
+	report.HeapsK = math.Pow(10, logK)
+
+	report.WordLength = histogram(wordLengths, 1, linearBins(1, 20))
+	report.SentenceLength = histogram(sentenceLengths, 1, linearBins(5, 100))
+	report.DocumentLength = histogram(documentLengths, 0, log2Bins)
+	return report
+}
+
+// document counts one line
+func (p *partial) document(line string, chunk int, pos *int64, opts Options) {
+	for _, r := range line {
+		p.chars[classOf(r, opts.Script)]++
+	}
+	length := 0
+	for _, sentence := range opts.Sentences(line) {
+		n := 0
+		opts.Tokenize(sentence, func(word string) {
+			if e := p.words[word]; e != nil {
+				e.count++
+			} else {
+				p.words[strings.Clone(word)] = &entry{count: 1, chunk: chunk, pos: *pos}
+			}
+			*pos++
+			n++
+		})
+		if n > 0 {
+			p.sentenceLengths[n]++
+			p.sentences++
+		}
+		length += n
+	}
+	// Every line is a document, those without a word have length 0
+	p.documentLengths[length]++
+}
+
+func classOf(r rune, script *unicode.RangeTable) int {
+	switch {
+	case unicode.IsSpace(r):
+		return classSpace
+	case script != nil && unicode.Is(script, r):
+		return classScript
+	case unicode.Is(unicode.Latin, r):
+		return classLatin
+	case r >= '0' && r <= '9':
+		return classDigit
+	case unicode.IsPunct(r) || unicode.IsSymbol(r):
+		return classPunct
+	}
+	return classOther
+}
+
+func clusterCount(word string) int {
+	n := 0
+	for i := 0; i < len(word); i += tokenizer.ClusterLen(word[i:]) {
+		n++
+	}
+	return n
+}
+
+// zipfPoints samples the rank/count curve at log-spaced ranks, so that the
+// fit is not dominated by the long tail
+func zipfPoints(counts []int64) []Point {
+	var points []Point
+	for rank := 1; rank <= len(counts); rank = max(rank+1, rank*5/4) {
+		points = append(points, Point{int64(rank), counts[rank-1]})
+	}
+	return points
+}
+
+// heapsCurve returns the number of types seen after every 1/n of the tokens
+func heapsCurve(firsts []int64, tokens int64, n int) []Point {
+	if tokens == 0 {
+		return nil
+	}
+	points := make([]Point, 0, n)
+	for k := 1; k <= n; k++ {
+		read := tokens * int64(k) / int64(n)
+		if read == 0 {
+			continue
+		}
+		seen := sort.Search(len(firsts), func(i int) bool { return firsts[i] >= read })
+		points = append(points, Point{read, int64(seen)})
+	}
+	return points
+}
+
+// fitLogLog fits log10 y = intercept + slope * log10 x by least squares
+func fitLogLog(points []Point) (slope, intercept, r2 float64) {
+	var n, sx, sy, sxx, sxy, syy float64
+	for _, p := range points {
+		if p.X <= 0 || p.Y <= 0 {
+			continue
+		}
+		x, y := math.Log10(float64(p.X)), math.Log10(float64(p.Y))
+		n++
+		sx += x
+		sy += y
+		sxx += x * x
+		sxy += x * y
+		syy += y * y
+	}
+	if n < 2 || n*sxx == sx*sx {
+		return 0, 0, 0
+	}
+	slope = (n*sxy - sx*sy) / (n*sxx - sx*sx)
+	intercept = (sy - slope*sx) / n
+	if d := (n*sxx - sx*sx) * (n*syy - sy*sy); d > 0 {
+		r := (n*sxy - sx*sy) / math.Sqrt(d)
+		r2 = r * r
+	}
+	return slope, intercept, r2
+}
+
+// binOf returns the bounds of the bin of a length
+type binOf func(length int) (from, to int)
+
+// linearBins puts lengths into bins of the given width, from 1 up to limit,
+// and everything longer into one last bin
+func linearBins(width, limit int) binOf {
+	return func(length int) (int, int) {
+		if length > limit {
+			return limit + 1, math.MaxInt
+		}
+		from := (length-1)/width*width + 1
+		return from, from + width - 1
+	}
+}
+
+// log2Bins puts lengths into bins of 0, 1, 2-3, 4-7, ...
+func log2Bins(length int) (int, int) {
+	if length == 0 {
+		return 0, 0
+	}
+	from := 1
+	for from*2 <= length {
+		from *= 2
+	}
+	return from, from*2 - 1
+}
+
+// histogram bins the lengths from first on
+func histogram(lengths map[int]int64, first int, bin binOf) Histogram {
+	var h Histogram
+	keys := make([]int, 0, len(lengths))
+	var total, sum int64
+	for length, count := range lengths {
+		keys = append(keys, length)
+		total += count
+		sum += int64(length) * count
+	}
+	if total == 0 {
+		return h
+	}
+	sort.Ints(keys)
+	h.Mean = float64(sum) / float64(total)
+	h.Max = keys[len(keys)-1]
+	var seen int64
+	for _, length := range keys {
+		count := lengths[length]
+		if seen < (total+1)/2 && seen+count >= (total+1)/2 {
+			h.Median = length
+		}
+		seen += count
+	}
+	// Empty bins are kept, so that histograms of two corpora line up
+	i := 0
+	for length := first; length <= h.Max; {
+		from, to := bin(length)
+		b := Bin{From: from, To: min(to, h.Max)}
+		for ; i < len(keys) && keys[i] <= b.To; i++ {
+			b.Count += lengths[keys[i]]
+		}
+		h.Bins = append(h.Bins, b)
+		length = b.To + 1
+	}
+	return h
+}
@@ -0,0 +1,299 @@
+// Package tokenizer splits Bangla text into words. Unlike a code-point range
+// regex it walks grapheme clusters, so hasanta-joined conjuncts, ZWJ/ZWNJ
+// inside conjuncts and hyphenated compounds (দক্ষিণ-পূর্ব) stay one word.
+// Other Indic scripts work the same way with their own virama.
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zwnj = '\u200C'
+	zwj  = '\u200D'
+)
+
+// Options controls what counts as part of a word
+type Options struct {
+	Script       *unicode.RangeTable // Script of the words, Bengali when nil
+	Digits       bool                // Treat digits of the script as word characters
+	Numbers      bool                // Emit numbers (script or ASCII digits) as separate tokens
+	Suffixes     bool                // Keep a word attached to a number (৫৭টি, ১৯৪৭-এ) in the number token
+	SplitHyphens bool                // Split hyphenated compounds into separate words
+	Latin        string              // What to do with Latin-script words, see the Latin* modes
+	Special      []string            // Placeholder tokens such as "<URL>" that are emitted whole
+}
+
+// Modes for Latin-script words in code-mixed text
+const (
+	LatinDrop  = ""      // Leave Latin words out
+	LatinKeep  = "keep"  // Keep them as written
+	LatinLower = "lower" // Keep them lowercased
+	LatinTag   = "tag"   // Keep them with LatinSuffix appended
+)
+
+// LatinSuffix marks Latin words in LatinTag mode, e.g. "BCB/Latn"
+const LatinSuffix = "/Latn"
+
+// Kinds of word clusters
+const (
+	notWord = iota
+	scriptWord
+	latinWord
+)
+
+// Validate checks the option values
+func (o Options) Validate() error {
+	switch o.Latin {
+	case LatinDrop, LatinKeep, LatinLower, LatinTag:
+		return nil
+	}
+	return fmt.Errorf("unknown latin mode %q, expected %s, %s or %s", o.Latin, LatinKeep, LatinLower, LatinTag)
+}
+
+// Tokenizer splits text into words
+type Tokenizer struct {
+	opts Options
+}
+
+// New creates a Tokenizer with the given options
+func New(opts Options) *Tokenizer {
+	if opts.Script == nil {
+		opts.Script = unicode.Bengali
+	}
+	return &Tokenizer{opts: opts}
+}
+
+var letters = New(Options{})
+
+// Words returns the Bangla words in text, without digits
+func Words(text string) []string {
+	return letters.Words(text)
+}
+
+// Words returns the words in text. The words are substrings of text.
+func (t *Tokenizer) Words(text string) []string {
+	var words []string
+	t.Each(text, func(word string) {
+		words = append(words, word)
+	})
+	return words
+}
+
+// Each calls fn for every word in text without allocating a slice
+func (t *Tokenizer) Each(text string, fn func(word string)) {
+	start, end, kind := -1, -1, notWord
+	flush := func() {
+		if start >= 0 {
+			word := text[start:end]
+			if kind == latinWord {
+				word = t.latin(word)
+			}
+			fn(word)
+		}
+		start, end = -1, -1
+	}
+
+	for i := 0; i < len(text); {
+		if n := t.specialLen(text[i:]); n > 0 {
+			flush()
+			fn(text[i : i+n])
+			i += n
+			continue
+		}
+		if t.opts.Numbers {
+			if n := t.numberLen(text[i:]); n > 0 {
+				if t.opts.Suffixes {
+					n += t.suffixLen(text[i+n:])
+				}
+				flush()
+				fn(text[i : i+n])
+				i += n
+				continue
+			}
+		}
+		size := ClusterLen(text[i:])
+		cluster := text[i : i+size]
+		clusterKind := t.clusterKind(cluster)
+		switch {
+		case clusterKind != notWord:
+			// Words of different scripts are never glued together
+			if start >= 0 && clusterKind != kind {
+				flush()
+			}
+			if start < 0 {
+				start, kind = i, clusterKind
+			}
+			// Trailing joiners are only kept if the word goes on
+			end = i + len(trimJoiners(cluster))
+		case isHyphen(cluster) && start >= 0 && !t.opts.SplitHyphens && t.kindAt(text[i+size:]) == kind:
+			// Keep compound hyphens, the next cluster extends the word
+		case isApostrophe(cluster) && start >= 0 && kind == latinWord && t.kindAt(text[i+size:]) == latinWord:
+			// Keep apostrophes inside Latin words ("don't")
+		default:
+			flush()
+		}
+		i += size
+	}
+	flush()
+}
+
+// ClusterLen returns the byte length of the grapheme cluster at the start of
+// s. A cluster is a base character followed by combining marks and joiners,
+// and consonants joined to it by a virama (hasanta), optionally through
+// ZWJ/ZWNJ.
+func ClusterLen(s string) int {
+	if s == "" {
+		return 0
+	}
+	first, size := utf8.DecodeRuneInString(s)
+	afterVirama := isVirama(first)
+	for size < len(s) {
+		r, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case r == zwj || r == zwnj:
+			// Joiners keep the virama state of the rune before them
+		case unicode.IsLetter(r) && afterVirama && r>>7 == first>>7:
+			// A consonant of the same script block forms a conjunct
+			afterVirama = false
+		case unicode.IsMark(r):
+			afterVirama = isVirama(r)
+		default:
+			return size
+		}
+		size += n
+	}
+	return size
+}
+
+// clusterKind tells whether a cluster belongs in a word of the script or in a
+// Latin word. Clusters made of a stray combining mark count as script words,
+// so broken text is not split mid-word.
+func (t *Tokenizer) clusterKind(cluster string) int {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	if t.opts.Latin != LatinDrop && unicode.Is(unicode.Latin, r) {
+		return latinWord
+	}
+	if !unicode.Is(t.opts.Script, r) {
+		return notWord
+	}
+	if unicode.IsDigit(r) {
+		if t.opts.Digits && !t.opts.Numbers {
+			return scriptWord
+		}
+		return notWord
+	}
+	if unicode.IsLetter(r) || unicode.IsMark(r) {
+		return scriptWord
+	}
+	return notWord
+}
+
+// latin applies the Latin mode to a Latin word
+func (t *Tokenizer) latin(word string) string {
+	switch t.opts.Latin {
+	case LatinLower:
+		return strings.ToLower(word)
+	case LatinTag:
+		return word + LatinSuffix
+	}
+	return word
+}
+
+// specialLen returns the byte length of the special token at the start of s,
+// or 0
+func (t *Tokenizer) specialLen(s string) int {
+	for _, special := range t.opts.Special {
+		if strings.HasPrefix(s, special) {
+			return len(special)
+		}
+	}
+	return 0
+}
+
+// numberLen returns the byte length of the number at the start of s, or 0.
+// A number is a run of digits with single '.' or ',' separators between them.
+func (t *Tokenizer) numberLen(s string) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if t.isDigit(r) {
+			n += size
+			continue
+		}
+		if n > 0 && (r == '.' || r == ',') && n+size < len(s) {
+			if next, _ := utf8.DecodeRuneInString(s[n+size:]); t.isDigit(next) {
+				n += size
+				continue
+			}
+		}
+		break
+	}
+	return n
+}
+
+// suffixLen returns the byte length of the script word attached to the start
+// of s, directly or by a hyphen, or 0
+func (t *Tokenizer) suffixLen(s string) int {
+	n := 0
+	for n < len(s) {
+		size := ClusterLen(s[n:])
+		if t.clusterKind(s[n:n+size]) == scriptWord {
+			n += size
+			continue
+		}
+		if n == 0 && isHyphen(s[:size]) && t.kindAt(s[size:]) == scriptWord {
+			n += size
+			continue
+		}
+		break
+	}
+	return n
+}
+
+func (t *Tokenizer) isDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (unicode.IsDigit(r) && unicode.Is(t.opts.Script, r))
+}
+
+// kindAt returns the kind of the cluster at the start of s
+func (t *Tokenizer) kindAt(s string) int {
+	if s == "" {
+		return notWord
+	}
+	return t.clusterKind(s[:ClusterLen(s)])
+}
+
+func trimJoiners(cluster string) string {
+	for len(cluster) > 0 {
+		r, n := utf8.DecodeLastRuneInString(cluster)
+		if r != zwj && r != zwnj {
+			break
+		}
+		cluster = cluster[:len(cluster)-n]
+	}
+	return cluster
+}
+
+func isApostrophe(cluster string) bool {
+	return cluster == "'" || cluster == "’"
+}
+
+func isHyphen(cluster string) bool {
+	switch cluster {
+	case "-", "‐", "‑":
+		return true
+	}
+	return false
+}
+
+// isVirama matches the vowel killers of the Indic scripts
+func isVirama(r rune) bool {
+	switch r {
+	case '\u094D', '\u09CD', '\u0A4D', '\u0ACD', '\u0B4D', '\u0BCD', '\u0C4D', '\u0CCD', '\u0D4D':
+		return true
+	}
+	return false
+}
@@ -2,47 +2,138 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"path/filepath"
 	"sort"
-	"sync"
+	"strings"
 	"time"
-)
+	"unicode"
+	"unicode/utf8"
 
-type WordCount struct {
-	word  string
-	count int
-}
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/coverage"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/numerals"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/scrub"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/sketch"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/vocab"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
 
 func main() {
-	inputFile := "/home/ovishek/NLP_BrainStorming/word2vecbangla/new_db/merged.txt"
-	numWorkers := 12 // Number of worker threads
-	top_n := 100     // Number of top words to output
-	outputFile := fmt.Sprintf("top_words_%d.txt", top_n)
+	inputPath := flag.String("input", "/home/ovishek/NLP_BrainStorming/word2vecbangla/new_db/merged.txt", "Corpus file to count")
+	workers := flag.Int("workers", 12, "Number of worker threads")
+	topN := flag.Int("top", 100, "Number of top words to output")
+	lang := flag.String("lang", "bn", "Language profile: "+strings.Join(profile.Codes(), ", "))
+	skipStopwords := flag.Bool("skip-stopwords", false, "Do not count the stopwords of the language")
+	numeralPolicy := flag.String("numerals", numerals.Keep, "Numeral policy: "+strings.Join(numerals.Policies, ", "))
+	latinMode := flag.String("latin", tokenizer.LatinDrop, "Latin-script words in code-mixed text: keep, lower, tag, or empty to drop")
+	memoryMB := flag.Int("memory", 0, "Memory budget in MB for the counts; above it sorted runs are spilled to disk (0 counts in memory)")
+	spillDir := flag.String("spill-dir", "", "Directory for spilled runs (the system temp directory when empty)")
+	approx := flag.Bool("approx", false, "Approximate the top words in bounded memory with Space-Saving; --input=- reads stdin")
+	counters := flag.Int("counters", 0, "Space-Saving counters per worker in --approx mode (100 times --top when 0)")
+	countsFile := flag.String("counts", "", "Also write all counts, sorted by word, to this file for the merge subcommand")
+	ngramOrder := flag.Int("ngram", 1, "Also count the n-grams of each sentence up to this order (2 for bigrams, 3 for trigrams)")
+	minCount := flag.Int("min-count", 1, "Leave words and n-grams counted fewer times than this out of the top lists (not the --counts file)")
+	vocabFile := flag.String("vocab", "", "Also write the vocabulary for an embedding trainer to this file, and keep probabilities to <name>.subsample.tsv")
+	vocabFormat := flag.String("vocab-format", vocab.Word2Vec, "Vocabulary format: "+strings.Join(vocab.Formats, ", "))
+	vocabMinCount := flag.Int("vocab-min-count", 5, "Leave words counted fewer times than this out of the vocabulary")
+	vocabMaxSize := flag.Int("vocab-max-size", 0, "Keep only this many of the most frequent words in the vocabulary (0 keeps all)")
+	special := flag.String("special", "", "Comma-separated special tokens to start the vocabulary with (the format's own when empty, none with -)")
+	sample := flag.Float64("sample", 1e-3, "Subsampling threshold for the keep probabilities of the vocabulary")
+	format := flag.String("format", coverage.Text, "Format of the top word list: "+strings.Join(coverage.Formats, ", ")+"; all but text add rank, relative frequency and cumulative coverage")
+	oovVocab := flag.String("oov-vocab", "", "Vocabulary file to measure the out-of-vocabulary rate of the input against")
 
-	// Open the input file
-	file, err := os.Open(inputFile)
-	if err != nil {
-		fmt.Printf("Error opening file: %v\n", err)
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		mergeCommand(os.Args[2:])
 		return
 	}
-	defer file.Close()
+	flag.Parse()
 
-	// Get file size for progress reporting
-	fileInfo, err := file.Stat()
+	langProfile, err := profile.Get(*lang)
 	if err != nil {
-		fmt.Printf("Error getting file stats: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	numbers, err := numerals.New(*numeralPolicy, langProfile, tokenizer.Options{Latin: *latinMode, Special: scrub.Tokens})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	totalSize := fileInfo.Size()
 
-	// Create channels
-	lines := make(chan string, 1000)
-	results := make(chan map[string]int, numWorkers)
-	progress := make(chan int64, 1000)
+	inputFile := *inputPath
+	numWorkers := *workers // Number of worker threads
+	top_n := *topN         // Number of top words to output
+	outputFile := topFileName(top_n, *format)
 
-	var wg sync.WaitGroup
+	// Dropped numbers and skipped stopwords are passed on as left out, so
+	// that they end the n-grams
+	tokens := func(line string, fn func(token string, keep bool)) {
+		numbers.EachToken(line, func(token string, keep bool) {
+			if keep && *skipStopwords && langProfile.IsStopword(langProfile.Normalize(token)) {
+				keep = false
+			}
+			fn(token, keep)
+		})
+	}
+	countWords := wordcount.Kept(tokens)
+
+	if err := coverage.ValidateFormat(*format); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := vocab.ValidateFormat(*vocabFormat); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	specialTokens := vocab.DefaultSpecial(*vocabFormat)
+	if *special == "-" {
+		specialTokens = nil
+	} else if *special != "" {
+		specialTokens = strings.Split(*special, ",")
+	}
+
+	// --approx only writes the top word list and its bounds
+	if *approx && (*ngramOrder > 1 || *countsFile != "" || *vocabFile != "" || *format != coverage.Text || *oovVocab != "" || *minCount > 1) {
+		fmt.Println("--approx cannot be combined with --ngram, --counts, --vocab, --format, --oov-vocab or --min-count")
+		return
+	}
+	if *ngramOrder > 1 {
+		countWords = wordcount.NGrams(tokens, langProfile.Sentences, *ngramOrder)
+	}
+
+	if *approx {
+		if err := approximateTop(inputFile, numWorkers, top_n, *counters, countWords); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		return
+	}
+	if inputFile == "-" {
+		fmt.Println("Reading from stdin needs --approx")
+		return
+	}
+
+	phases := wordcount.StartPhases("count")
+
+	// Map the input file
+	file, err := wordcount.Open(inputFile)
+	if err != nil {
+		fmt.Printf("Error opening file: %v\n", err)
+		return
+	}
+	defer file.Close()
+	totalSize := int64(len(file.Data))
+	var lines int64
+	if *vocabFile != "" {
+		lines = countLines(file.Data)
+	}
+
+	progress := make(chan int64, numWorkers)
+	monitorDone := make(chan struct{})
 
 	// Start progress monitor
 	go func() {
@@ -58,92 +149,540 @@ func main() {
 			fmt.Printf("\rProgress: %.2f%% (%.2f MB/s)", percentage, speed)
 		}
 		fmt.Println()
+		close(monitorDone)
 	}()
 
-	// Start worker goroutines
-	bengaliRegex := regexp.MustCompile(`[\p{Bengali}]+`)
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func(workerId int) {
-			defer wg.Done()
-			wordCounts := make(map[string]int)
-			
-			for line := range lines {
-				matches := bengaliRegex.FindAllString(line, -1)
-				for _, word := range matches {
-					wordCounts[word]++
-				}
-			}
-			
-			results <- wordCounts
+	// Workers count whole chunks of the file, in memory or, with a memory
+	// budget, spilling sorted runs to disk
+	countOpts := wordcount.Options{
+		Workers:  numWorkers,
+		Progress: func(bytes int64) { progress <- bytes },
+		Done: func(workerId int) {
 			fmt.Printf("\nWorker %d completed\n", workerId)
-		}(i)
+		},
+	}
+	var partial *wordcount.Partial
+	var runs *wordcount.Runs
+	if *memoryMB > 0 {
+		runs, err = wordcount.CountToDisk(file.Data, countWords, countOpts, int64(*memoryMB)<<20, *spillDir)
+	} else {
+		partial = wordcount.Count(file.Data, countWords, countOpts)
+	}
+	close(progress)
+	<-monitorDone
+	if err != nil {
+		fmt.Printf("Error counting words: %v\n", err)
+		return
 	}
 
-	// Read file and send lines to workers
-	scanner := bufio.NewScanner(file)
-	scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // Increase buffer size
-
-	go func() {
-		for scanner.Scan() {
-			line := scanner.Text()
-			lines <- line
-			progress <- int64(len(line) + 1) // +1 for newline
+	// Select the top words while streaming over the counts: the in-memory
+	// shards, merged in parallel, or the k-way merge of the spilled runs
+	phases.Next("merge")
+	tables := newTopTables(top_n, langProfile, *latinMode != tokenizer.LatinDrop)
+	// --min-count only prunes the tables, the count file gets every word
+	pruned := 0
+	add := tables.tally(pruneBelow(*minCount, &pruned, tables.add))
+	var countWriter *wordcount.CountWriter
+	var countErr error
+	if *countsFile != "" {
+		// Full counts in word order, for the merge subcommand
+		countWriter, err = wordcount.CreateCountFile(*countsFile)
+		if err != nil {
+			fmt.Printf("Error creating count file: %v\n", err)
+			return
 		}
-		close(lines)
-	}()
+		next := add
+		add = func(word string, count int) {
+			if countErr == nil {
+				countErr = countWriter.Write(word, count)
+			}
+			next(word, count)
+		}
+	}
+	var collector *vocab.Collector
+	if *vocabFile != "" {
+		// The vocabulary applies its own cutoffs to all words
+		collector = vocab.NewCollector(*vocabMinCount, *vocabMaxSize)
+		next := add
+		add = func(word string, count int) {
+			if wordcount.Order(word) == 1 {
+				collector.Add(word, count)
+			}
+			next(word, count)
+		}
+	}
+	var oovTokens, oovTypes int64
+	if *oovVocab != "" {
+		known, err := coverage.ReadVocab(*oovVocab)
+		if err != nil {
+			fmt.Printf("Error reading vocabulary: %v\n", err)
+			return
+		}
+		next := add
+		add = func(word string, count int) {
+			if wordcount.Order(word) == 1 && !known[word] {
+				oovTokens += int64(count)
+				oovTypes++
+			}
+			next(word, count)
+		}
+	}
+	if runs != nil {
+		defer runs.Close()
+		fmt.Printf("Merging %d spilled runs\n", runs.Len())
+		if err := runs.Each(add); err != nil {
+			fmt.Printf("Error merging spilled runs: %v\n", err)
+			return
+		}
+	} else {
+		finalCounts := partial.Merge(numWorkers)
+		partial = nil
+		phases.Next("select")
+		if countWriter != nil {
+			finalCounts.EachSorted(add)
+		} else {
+			finalCounts.Each(add)
+		}
+	}
+	if countWriter != nil {
+		if err := countWriter.Close(); countErr == nil {
+			countErr = err
+		}
+		if countErr != nil {
+			fmt.Printf("Error writing count file: %v\n", countErr)
+			return
+		}
+	}
 
-	// Wait for workers and close results channel
-	go func() {
-		wg.Wait()
-		close(results)
-		close(progress)
-	}()
+	// Write top words to output file
+	phases.Next("write")
+	report, err := writeTopTable(outputFile, *format, tables)
+	if err != nil {
+		fmt.Printf("Error writing to output file: %v\n", err)
+		return
+	}
+	if *oovVocab != "" {
+		fmt.Printf("Out of vocabulary (%s): %.2f%% of %d tokens, %d of %d distinct words\n",
+			*oovVocab, 100*float64(oovTokens)/float64(max(report.Tokens, 1)), report.Tokens, oovTypes, report.Types)
+	}
 
-	// Merge results
-	finalCounts := make(map[string]int)
-	for workerCounts := range results {
-		for word, count := range workerCounts {
-			finalCounts[word] += count
+	// With Latin words kept, also write one table per script
+	for script, top := range tables.scripts {
+		scriptFile := fmt.Sprintf("top_words_%d_%s.txt", top_n, script)
+		if err := writeTopWords(scriptFile, top.Sorted()); err != nil {
+			fmt.Printf("Error writing to output file: %v\n", err)
+			return
+		}
+		if pruned > 0 {
+			fmt.Printf("%s: %d words kept, written to %s\n", script, tables.scriptDistinct[script], scriptFile)
+		} else {
+			fmt.Printf("%s: %d distinct words, written to %s\n", script, tables.scriptDistinct[script], scriptFile)
+		}
+	}
+	if err := tables.writeNGrams(); err != nil {
+		fmt.Printf("Error writing to output file: %v\n", err)
+		return
+	}
+	if collector != nil {
+		if err := writeVocabulary(*vocabFile, *vocabFormat, specialTokens, collector, lines, *sample); err != nil {
+			fmt.Printf("Error writing vocabulary: %v\n", err)
+			return
 		}
 	}
+	if pruned > 0 {
+		fmt.Printf("%d words and n-grams counted fewer than %d times left out\n", pruned, *minCount)
+	}
+	fmt.Printf("%s\n%s", tables.describe(report), wordcount.FormatPhases(phases.Stop()))
+}
 
-	// Convert to slice for sorting
-	var wordCounts []WordCount
-	for word, count := range finalCounts {
-		wordCounts = append(wordCounts, WordCount{word, count})
+// mergeCommand combines count files written with --counts, e.g. of the
+// separately downloaded parts, into one count file and top word list
+func mergeCommand(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	outputFile := fs.String("output", "", "Merged count file to write (optional)")
+	topN := fs.Int("top", 100, "Number of top words to output")
+	format := fs.String("format", coverage.Text, "Format of the top word list: "+strings.Join(coverage.Formats, ", "))
+	minCount := fs.Int("min-count", 1, "Leave words and n-grams counted fewer times than this in total out of the top lists (not the --output file)")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fmt.Println("Usage: go run top_word_finder.go merge [--output=all.counts] [--top=100] part-1.counts part-2.counts ...")
+		return
+	}
+	if err := coverage.ValidateFormat(*format); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
 	}
 
-	// Sort by count (descending) and then by word
-	sort.Slice(wordCounts, func(i, j int) bool {
-		if wordCounts[i].count != wordCounts[j].count {
-			return wordCounts[i].count > wordCounts[j].count
+	phases := wordcount.StartPhases("merge")
+	tables := newTopTables(*topN, nil, false)
+	// --min-count only prunes the tables, the merged count file gets every word
+	pruned := 0
+	add := tables.tally(pruneBelow(*minCount, &pruned, tables.add))
+	var countWriter *wordcount.CountWriter
+	var countErr error
+	if *outputFile != "" {
+		var err error
+		countWriter, err = wordcount.CreateCountFile(*outputFile)
+		if err != nil {
+			fmt.Printf("Error creating count file: %v\n", err)
+			return
 		}
-		return wordCounts[i].word < wordCounts[j].word
-	})
+		next := add
+		add = func(word string, count int) {
+			if countErr == nil {
+				countErr = countWriter.Write(word, count)
+			}
+			next(word, count)
+		}
+	}
+	if err := wordcount.MergeCountFiles(fs.Args(), add); err != nil {
+		fmt.Printf("Error merging count files: %v\n", err)
+		return
+	}
+	if countWriter != nil {
+		if err := countWriter.Close(); countErr == nil {
+			countErr = err
+		}
+		if countErr != nil {
+			fmt.Printf("Error writing count file: %v\n", countErr)
+			return
+		}
+	}
 
-	// Write top 200 words to output file
-	outFile, err := os.Create(outputFile)
+	phases.Next("write")
+	report, err := writeTopTable(topFileName(*topN, *format), *format, tables)
 	if err != nil {
-		fmt.Printf("Error creating output file: %v\n", err)
+		fmt.Printf("Error writing to output file: %v\n", err)
+		return
+	}
+	if err := tables.writeNGrams(); err != nil {
+		fmt.Printf("Error writing to output file: %v\n", err)
 		return
 	}
+	if pruned > 0 {
+		fmt.Printf("%d words and n-grams counted fewer than %d times left out\n", pruned, *minCount)
+	}
+	fmt.Printf("Merged %d count files, %s\n%s", fs.NArg(), tables.describe(report), wordcount.FormatPhases(phases.Stop()))
+}
+
+// pruneBelow passes on only the words counted at least minCount times and
+// counts the others in pruned
+func pruneBelow(minCount int, pruned *int, add func(word string, count int)) func(word string, count int) {
+	if minCount <= 1 {
+		return add
+	}
+	return func(word string, count int) {
+		if count < minCount {
+			*pruned++
+			return
+		}
+		add(word, count)
+	}
+}
+
+// topTables selects the most frequent words overall and, with Latin words
+// kept, per script. N-grams get one table per order.
+type topTables struct {
+	n              int
+	lang           *profile.Profile
+	perScript      bool
+	all            *wordcount.Top
+	distinct       int
+	scripts        map[string]*wordcount.Top
+	scriptDistinct map[string]int
+	ngrams         map[int]*wordcount.Top
+	ngramDistinct  map[int]int
+	types          map[int]int // Number of words with each count, pruned ones included, for the coverage
+}
+
+func newTopTables(n int, lang *profile.Profile, perScript bool) *topTables {
+	return &topTables{
+		n:              n,
+		lang:           lang,
+		perScript:      perScript,
+		all:            wordcount.NewTop(n),
+		scripts:        make(map[string]*wordcount.Top),
+		scriptDistinct: make(map[string]int),
+		ngrams:         make(map[int]*wordcount.Top),
+		ngramDistinct:  make(map[int]int),
+		types:          make(map[int]int),
+	}
+}
+
+// add offers one word or n-gram with its total count to the tables
+func (t *topTables) add(word string, count int) {
+	if order := wordcount.Order(word); order > 1 {
+		top := t.ngrams[order]
+		if top == nil {
+			top = wordcount.NewTop(t.n)
+			t.ngrams[order] = top
+		}
+		top.Add(word, count)
+		t.ngramDistinct[order]++
+		return
+	}
+	t.distinct++
+	t.all.Add(word, count)
+	if !t.perScript {
+		return
+	}
+	script := scriptOf(word, t.lang)
+	top := t.scripts[script]
+	if top == nil {
+		top = wordcount.NewTop(t.n)
+		t.scripts[script] = top
+	}
+	top.Add(word, count)
+	t.scriptDistinct[script]++
+}
+
+// describe returns the number of distinct words of the coverage report and,
+// if --min-count left some out, how many of them were kept
+func (t *topTables) describe(report *coverage.Report) string {
+	if t.distinct == report.Types {
+		return fmt.Sprintf("%d distinct words", report.Types)
+	}
+	return fmt.Sprintf("%d distinct words, %d kept", report.Types, t.distinct)
+}
+
+// tally records the count of every word for the coverage before passing it
+// on to add
+func (t *topTables) tally(add func(word string, count int)) func(word string, count int) {
+	return func(word string, count int) {
+		if wordcount.Order(word) == 1 {
+			t.types[count]++
+		}
+		add(word, count)
+	}
+}
+
+// topFileName names the top word list of a format
+func topFileName(n int, format string) string {
+	if format == coverage.Text {
+		return fmt.Sprintf("top_words_%d.txt", n)
+	}
+	return fmt.Sprintf("top_words_%d.%s", n, format)
+}
+
+// writeTopTable writes the top words in a format and prints how many of the
+// most frequent words cover 90, 95 and 99% of the tokens
+func writeTopTable(path, format string, tables *topTables) (*coverage.Report, error) {
+	report := coverage.NewReport(tables.all.Sorted(), tables.types, coverage.DefaultLevels)
+	outFile, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer outFile.Close()
+	if err := report.Write(outFile, format); err != nil {
+		return nil, err
+	}
+	levels := make([]string, len(report.Levels))
+	for i, level := range report.Levels {
+		levels[i] = fmt.Sprintf("%g%% by %d", 100*level.Coverage, level.Words)
+	}
+	fmt.Printf("Coverage of the %d tokens by the most frequent words: %s\n", report.Tokens, strings.Join(levels, ", "))
+	return report, nil
+}
+
+// writeNGrams writes the top n-grams of each order to top_<order>grams_<n>.txt
+func (t *topTables) writeNGrams() error {
+	orders := make([]int, 0, len(t.ngrams))
+	for order := range t.ngrams {
+		orders = append(orders, order)
+	}
+	sort.Ints(orders)
+	for _, order := range orders {
+		ngramFile := fmt.Sprintf("top_%dgrams_%d.txt", order, t.n)
+		if err := writeTopWords(ngramFile, t.ngrams[order].Sorted()); err != nil {
+			return err
+		}
+		fmt.Printf("%d-grams: %d distinct, written to %s\n", order, t.ngramDistinct[order], ngramFile)
+	}
+	return nil
+}
+
+// writeVocabulary writes the vocabulary of an embedding trainer and, next to
+// it, the keep probabilities of subsampling. </s> is counted once per line
+// and <unk> gets the tokens of the words left out.
+func writeVocabulary(path, format string, special []string, collector *vocab.Collector, lines int64, sample float64) error {
+	words := collector.Words()
+	var kept int64
+	for _, wc := range words {
+		kept += int64(wc.Count)
+	}
+	total := kept
+	specials := make([]wordcount.WordCount, len(special))
+	for i, token := range special {
+		var count int64
+		switch token {
+		case vocab.EOS:
+			count = lines
+		case vocab.Unknown:
+			count = collector.Tokens - kept
+		}
+		specials[i] = wordcount.WordCount{Word: token, Count: int(count)}
+		total += count
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := vocab.Write(f, format, specials, words); err != nil {
+		return err
+	}
+
+	subsampleFile := strings.TrimSuffix(path, filepath.Ext(path)) + ".subsample.tsv"
+	sf, err := os.Create(subsampleFile)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+	if err := vocab.WriteSubsampling(sf, append(specials, words...), total, sample); err != nil {
+		return err
+	}
+	fmt.Printf("Vocabulary of %d words and %d special tokens (%.2f%% of the tokens) written to %s in %s format, keep probabilities to %s\n",
+		len(words), len(specials), 100*float64(kept)/float64(max(collector.Tokens, 1)), path, format, subsampleFile)
+	return nil
+}
+
+// countLines counts the lines of data, including an unterminated last line
+func countLines(data []byte) int64 {
+	lines := int64(bytes.Count(data, []byte{'\n'}))
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
+// writeTopWords writes "count word" lines of sorted counts
+func writeTopWords(path string, wordCounts []wordcount.WordCount) error {
+	outFile, err := os.Create(path)
+	if err != nil {
+		return err
+	}
 	defer outFile.Close()
 
 	writer := bufio.NewWriter(outFile)
-	for i := 0; i < min(top_n, len(wordCounts)); i++ {
-		_, err := fmt.Fprintf(writer, "%d %s\n", wordCounts[i].count, wordCounts[i].word)
+	for _, wc := range wordCounts {
+		_, err := fmt.Fprintf(writer, "%d %s\n", wc.Count, wc.Word)
 		if err != nil {
-			fmt.Printf("Error writing to output file: %v\n", err)
-			return
+			return err
 		}
 	}
-	writer.Flush()
+	return writer.Flush()
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// approximateTop finds the top words in bounded memory with one Space-Saving
+// summary per worker. The input is read as a stream, so it can be a pipe.
+func approximateTop(path string, numWorkers, n, counters int, tokenize wordcount.Tokenize) error {
+	input := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	if counters <= 0 {
+		counters = 100 * n
+	}
+	summaries := make([]*sketch.SpaceSaving, numWorkers)
+	for i := range summaries {
+		var err error
+		if summaries[i], err = sketch.NewSpaceSaving(counters); err != nil {
+			return err
+		}
+	}
+
+	// Progress shows what has been read, the total size is not known. The
+	// workers send their block sizes to a single monitor, which prints.
+	progress := make(chan int64, numWorkers)
+	monitorDone := make(chan struct{})
+	go func() {
+		var read int64
+		startTime := time.Now()
+		for bytes := range progress {
+			read += bytes
+			mb := float64(read) / (1024 * 1024)
+			fmt.Printf("\rRead: %.2f MB (%.2f MB/s)", mb, mb/time.Since(startTime).Seconds())
+		}
+		fmt.Println()
+		close(monitorDone)
+	}()
+	err := wordcount.Stream(input, numWorkers, func(worker int, block []byte) {
+		summary := summaries[worker]
+		wordcount.EachLine(block, func(line string) {
+			tokenize(line, summary.Add)
+		})
+		progress <- int64(len(block))
+	})
+	close(progress)
+	<-monitorDone
+	if err != nil {
+		return err
+	}
+
+	summary := summaries[0]
+	for _, other := range summaries[1:] {
+		summary.Merge(other)
+	}
+	top := summary.Top(n)
+
+	words := make([]wordcount.WordCount, len(top))
+	for i, item := range top {
+		words[i] = wordcount.WordCount{Word: item.Key, Count: int(item.Count)}
+	}
+	outputFile := fmt.Sprintf("top_words_%d.txt", n)
+	if err := writeTopWords(outputFile, words); err != nil {
+		return err
+	}
+
+	// Per-word bounds: the true count is between the lower bound and the estimate
+	boundsFile := fmt.Sprintf("top_words_%d_bounds.tsv", n)
+	f, err := os.Create(boundsFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	writer := bufio.NewWriter(f)
+	fmt.Fprintln(writer, "word\testimate\tlower_bound\tguaranteed")
+	guaranteed := 0
+	var maxError uint64
+	for _, item := range top {
+		fmt.Fprintf(writer, "%s\t%d\t%d\t%t\n", item.Key, item.Count, item.Count-item.Error, item.Guaranteed)
+		if item.Guaranteed {
+			guaranteed++
+		}
+		maxError = max(maxError, item.Error)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	total := summary.Total()
+	fmt.Printf("Space-Saving with %d counters per worker over %d tokens\n", counters, total)
+	fmt.Printf("Counts overestimate by at most %d here (bound N/k = %d); unlisted words occur at most %d times\n",
+		maxError, total/uint64(counters), summary.Floor())
+	fmt.Printf("%d of %d words are guaranteed to be in the top %d; bounds written to %s\n", guaranteed, len(top), n, boundsFile)
+	return nil
+}
+
+// scriptOf names the frequency table a word belongs in
+func scriptOf(word string, lang *profile.Profile) string {
+	if word == numerals.NumToken || numerals.IsNumber(word) {
+		return "num"
+	}
+	for _, token := range scrub.Tokens {
+		if word == token {
+			return "placeholder"
+		}
+	}
+	r, _ := utf8.DecodeRuneInString(word)
+	if unicode.Is(unicode.Latin, r) {
+		return "latin"
 	}
-	return b
+	return lang.Code
 }
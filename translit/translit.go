@@ -0,0 +1,239 @@
+// Package translit romanizes Bangla text with ISO 15919 and converts it back.
+// The romanization is reversible for canonical Bangla text: where two
+// letters would read as one (ক্হ vs খ, অই vs ঐ) a ':' separates them, as the
+// standard prescribes.
+package translit
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+)
+
+const (
+	hasanta = '\u09CD'
+	nukta   = '\u09BC'
+	zwnj    = '\u200C'
+	zwj     = '\u200D'
+)
+
+var consonants = map[rune]string{
+	'ক': "k", 'খ': "kh", 'গ': "g", 'ঘ': "gh", 'ঙ': "ṅ",
+	'চ': "c", 'ছ': "ch", 'জ': "j", 'ঝ': "jh", 'ঞ': "ñ",
+	'ট': "ṭ", 'ঠ': "ṭh", 'ড': "ḍ", 'ঢ': "ḍh", 'ণ': "ṇ",
+	'ত': "t", 'থ': "th", 'দ': "d", 'ধ': "dh", 'ন': "n",
+	'প': "p", 'ফ': "ph", 'ব': "b", 'ভ': "bh", 'ম': "m",
+	'য': "y", 'র': "r", 'ল': "l", 'শ': "ś", 'ষ': "ṣ",
+	'স': "s", 'হ': "h",
+	'\u09DC': "ṛ", '\u09DD': "ṛh", '\u09DF': "ẏ", // ড়, ঢ়, য়
+	'ৰ': "ṟ", 'ৱ': "w", // Assamese ra and wa
+}
+
+// Vocalic r and l carry a combining ring below, long ones a macron on top
+var vowels = map[rune]string{
+	'অ': "a", 'আ': "ā", 'ই': "i", 'ঈ': "ī", 'উ': "u", 'ঊ': "ū",
+	'ঋ': "r\u0325", 'ৠ': "r\u0325\u0304", 'ঌ': "l\u0325", 'ৡ': "l\u0325\u0304",
+	'এ': "ē", 'ঐ': "ai", 'ও': "ō", 'ঔ': "au",
+}
+
+var vowelSigns = map[rune]string{
+	'\u09BE': "ā", '\u09BF': "i", '\u09C0': "ī", '\u09C1': "u", '\u09C2': "ū",
+	'\u09C3': "r\u0325", '\u09C4': "r\u0325\u0304", '\u09E2': "l\u0325", '\u09E3': "l\u0325\u0304",
+	'\u09C7': "ē", '\u09C8': "ai", '\u09CB': "ō", '\u09CC': "au",
+}
+
+var signs = map[rune]string{
+	'\u0982': "ṁ", '\u0983': "ḥ", '\u0981': "m\u0310", // ং ঃ ঁ
+	'\u09BD': "ʼ", // Avagraha, as a modifier letter so that apostrophes in the text stay apostrophes
+	'\u09CE': "ṯ", // Khanda ta never carries a vowel, so it is read like a sign
+	'\u09CD': "ˇ", // A hasanta after a vowel, as in অ্যা, which the standard does not cover
+}
+
+// Reverse tables, and all romanized tokens longest first for greedy parsing
+var (
+	romanConsonants = invert(consonants)
+	romanVowels     = invert(vowels)
+	romanVowelSigns = invert(vowelSigns)
+	romanSigns      = invert(signs)
+	tokens          []string
+)
+
+func init() {
+	seen := make(map[string]bool)
+	for _, table := range []map[string]rune{romanConsonants, romanVowels, romanSigns} {
+		for roman := range table {
+			if !seen[roman] {
+				seen[roman] = true
+				tokens = append(tokens, roman)
+			}
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if len(tokens[i]) != len(tokens[j]) {
+			return len(tokens[i]) > len(tokens[j])
+		}
+		return tokens[i] < tokens[j]
+	})
+}
+
+func invert(m map[rune]string) map[string]rune {
+	inv := make(map[string]rune, len(m))
+	for r, s := range m {
+		inv[s] = r
+	}
+	return inv
+}
+
+// ToISO15919 romanizes Bangla text. Other characters are kept, except that
+// Bangla digits become ASCII digits and the danda becomes a period.
+func ToISO15919(text string) string {
+	runes := []rune(normalize.Canonicalize(text, nil))
+	var b strings.Builder
+	last := "" // Last romanized token, to detect ambiguous joins
+
+	write := func(roman string) {
+		if last != "" && joinsWith(last, roman) {
+			b.WriteByte(':')
+		}
+		b.WriteString(roman)
+		last = roman
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case consonants[r] != "":
+			write(consonants[r])
+			next := rune(0)
+			if i+1 < len(runes) {
+				next = runes[i+1]
+			}
+			if next == nukta && i+2 < len(runes) {
+				i++
+				next = runes[i+1]
+			}
+			switch {
+			case vowelSigns[next] != "":
+				write(vowelSigns[next])
+				i++
+			case next == hasanta:
+				i++
+				// Joiners after a hasanta only select a glyph form
+				for i+1 < len(runes) && (runes[i+1] == zwj || runes[i+1] == zwnj) {
+					i++
+				}
+				// Keep the hasanta visible where a vowel would otherwise
+				// be read as the vowel sign of the consonant
+				if i+1 < len(runes) && vowels[runes[i+1]] != "" {
+					write(signs[hasanta])
+				}
+			default:
+				write("a") // Inherent vowel
+			}
+		case vowels[r] != "":
+			write(vowels[r])
+		case signs[r] != "":
+			write(signs[r])
+		case r >= '০' && r <= '৯':
+			b.WriteRune('0' + r - '০')
+			last = ""
+		case r == '।':
+			b.WriteByte('.')
+			last = ""
+		case r == nukta || r == zwj || r == zwnj:
+			// Dropped, they have no romanized form on their own
+		default:
+			b.WriteRune(r)
+			last = ""
+		}
+	}
+	return b.String()
+}
+
+// joinsWith reports whether a reader would take last and the start of next
+// as one longer token
+func joinsWith(last, next string) bool {
+	joined := last + next
+	for _, t := range tokens {
+		if len(t) > len(last) && strings.HasPrefix(joined, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromISO15919 converts ISO 15919 romanized text back to Bangla. ASCII
+// digits become Bangla digits; other unknown characters are kept.
+func FromISO15919(text string) string {
+	var b strings.Builder
+	afterConsonant := false // A consonant is waiting for its vowel
+
+	endConsonant := func() {
+		if afterConsonant {
+			b.WriteRune(hasanta)
+			afterConsonant = false
+		}
+	}
+
+	for i := 0; i < len(text); {
+		if text[i] == ':' && i+1 < len(text) && isTokenStart(text[i+1:]) {
+			i++ // Separator between letters that would otherwise join
+			continue
+		}
+		token := matchToken(text[i:])
+		if token == "" {
+			r, size := utf8.DecodeRuneInString(text[i:])
+			endConsonant()
+			if r >= '0' && r <= '9' {
+				r = '০' + r - '0'
+			}
+			b.WriteRune(r)
+			i += size
+			continue
+		}
+		i += len(token)
+
+		if c, ok := romanConsonants[token]; ok {
+			if afterConsonant {
+				b.WriteRune(hasanta) // Conjunct
+			}
+			b.WriteRune(c)
+			afterConsonant = true
+			continue
+		}
+		if v, ok := romanVowels[token]; ok {
+			if afterConsonant {
+				if token != "a" {
+					b.WriteRune(romanVowelSigns[token])
+				}
+				afterConsonant = false
+				continue
+			}
+			b.WriteRune(v)
+			continue
+		}
+		if romanSigns[token] == hasanta && afterConsonant {
+			endConsonant() // Explicit hasanta before a vowel
+			continue
+		}
+		endConsonant()
+		b.WriteRune(romanSigns[token])
+	}
+	endConsonant()
+	return b.String()
+}
+
+func matchToken(s string) string {
+	for _, t := range tokens {
+		if strings.HasPrefix(s, t) {
+			return t
+		}
+	}
+	return ""
+}
+
+func isTokenStart(s string) bool {
+	return matchToken(s) != ""
+}
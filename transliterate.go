@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/translit"
+)
+
+func main() {
+	inputFile := flag.String("input", "", "Text file to romanize, or romanized text with --reverse")
+	outputFile := flag.String("output", "", "Output file (stdout when empty)")
+	reverse := flag.Bool("reverse", false, "Convert ISO 15919 romanized text back to Bangla")
+	check := flag.Bool("check", false, "Round-trip the titles in the files given as arguments and report mismatches")
+	flag.Parse()
+
+	if *check {
+		if flag.NArg() == 0 {
+			fmt.Println("Usage: go run transliterate.go --check inputs/titles-part-1.txt [more title files]")
+			os.Exit(1)
+		}
+		failed := 0
+		for _, path := range flag.Args() {
+			n, err := checkRoundTrip(path)
+			if err != nil {
+				fmt.Printf("Error checking %s: %v\n", path, err)
+				os.Exit(1)
+			}
+			failed += n
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *inputFile == "" {
+		fmt.Println("Usage: go run transliterate.go --input=cleaned.txt [--output=romanized.txt] [--reverse]")
+		os.Exit(1)
+	}
+	convert := translit.ToISO15919
+	if *reverse {
+		convert = translit.FromISO15919
+	}
+	input, err := os.Open(*inputFile)
+	if err != nil {
+		fmt.Printf("Error opening input file: %v\n", err)
+		os.Exit(1)
+	}
+	defer input.Close()
+	output := os.Stdout
+	if *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	reader := bufio.NewReader(input)
+	writer := bufio.NewWriter(output)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, werr := writer.WriteString(convert(line)); werr != nil {
+				fmt.Printf("Error writing output: %v\n", werr)
+				os.Exit(1)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Printf("Error reading input file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// checkRoundTrip romanizes every Bangla title in a title list, converts it
+// back and compares it with the canonical title. It prints the first
+// mismatches and returns how many there were.
+func checkRoundTrip(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	checked, skipped, failed := 0, 0, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		title := strings.TrimSpace(scanner.Text())
+		if !isRomanizable(title) {
+			skipped++
+			continue
+		}
+		checked++
+		// Joiners only select glyph forms and have no romanized form
+		want := strings.NewReplacer("\u200C", "", "\u200D", "").Replace(normalize.Canonicalize(title, nil))
+		roman := translit.ToISO15919(title)
+		if got := translit.FromISO15919(roman); got != want {
+			failed++
+			if failed <= 20 {
+				fmt.Printf("Mismatch: %s -> %s -> %s\n", title, roman, got)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return failed, err
+	}
+	fmt.Printf("%s: %d titles checked, %d failed, %d skipped (not Bangla-only)\n", path, checked, failed, skipped)
+	return failed, nil
+}
+
+// isRomanizable reports whether a title is Bangla text whose romanization
+// can be read back unambiguously: no Latin letters or ASCII digits, which
+// would come back as Bangla, and no danda or colon, which the romanization
+// itself uses
+func isRomanizable(title string) bool {
+	bangla := false
+	for _, r := range title {
+		switch {
+		case unicode.Is(unicode.Bengali, r):
+			bangla = true
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '।', r == ':', r == '.':
+			return false
+		}
+	}
+	return bangla
+}
@@ -0,0 +1,222 @@
+// Package validate finds orthographically impossible Bangla sequences and
+// leftovers of stripped templates in extracts, and can repair them.
+package validate
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of problems the validator reports
+const (
+	KindOrphanVowelSign  = "orphan_vowel_sign"
+	KindOrphanSign       = "orphan_sign"
+	KindDoubledHasanta   = "doubled_hasanta"
+	KindStackedVowelSign = "stacked_vowel_sign"
+	KindEmptyBrackets    = "empty_brackets"
+	KindSplitVowel       = "split_vowel_letter" // আ typed as অ + া, only flagged: canonicalization composes it
+)
+
+const (
+	hasanta     = '\u09CD'
+	nukta       = '\u09BC'
+	candrabindu = '\u0981'
+	anusvara    = '\u0982'
+	visarga     = '\u0983'
+	zwnj        = '\u200C'
+	zwj         = '\u200D'
+)
+
+// Empty brackets are what is left of pronunciation and IPA templates, e.g. "বাংলাদেশ ()"
+var emptyBracketsRegex = regexp.MustCompile(`\s?[(\[{]\s*[,;:।\-–—\s]*[)\]}]`)
+
+// Issue is one problem found in a text
+type Issue struct {
+	Kind    string
+	Offset  int    // Byte offset where the problem was found
+	Context string // Surrounding text for review
+}
+
+// Check returns the problems in text without changing it
+func Check(text string) []Issue {
+	_, issues := scan(text, false)
+	return issues
+}
+
+// Repair removes orphaned signs, extra hasantas and vowel signs, and empty
+// brackets. It returns the repaired text and the problems it fixed.
+func Repair(text string) (string, []Issue) {
+	return scan(text, true)
+}
+
+// scan reports offsets into the input text. When repairing, the empty
+// brackets are skipped rather than cut out first, so that the offsets of the
+// signs after them stay valid.
+func scan(text string, repair bool) (string, []Issue) {
+	var issues []Issue
+	brackets := emptyBracketsRegex.FindAllStringIndex(text, -1)
+	for _, loc := range brackets {
+		issues = append(issues, newIssue(KindEmptyBrackets, text, loc[0]))
+	}
+
+	var b strings.Builder
+	prev := rune(0) // Previous rune, ignoring joiners
+	for offset, r := range text {
+		if repair && len(brackets) > 0 && offset >= brackets[0][0] {
+			if offset < brackets[0][1] {
+				continue
+			}
+			brackets = brackets[1:]
+			if len(brackets) > 0 && offset >= brackets[0][0] {
+				continue // Adjacent brackets
+			}
+		}
+		if kind := classify(prev, r); kind != "" {
+			issues = append(issues, newIssue(kind, text, offset))
+			if repair && kind != KindSplitVowel {
+				continue
+			}
+		}
+		if repair {
+			b.WriteRune(r)
+		}
+		if r != zwj && r != zwnj {
+			prev = r
+		}
+	}
+
+	if !repair {
+		return text, issues
+	}
+	return b.String(), issues
+}
+
+// classify returns the kind of problem r causes after prev, or ""
+func classify(prev, r rune) string {
+	switch {
+	case isVowelSign(r):
+		if prev == 'অ' && r == '\u09BE' {
+			return KindSplitVowel
+		}
+		// ে + া and ে + ৗ are the decomposed forms of ো and ৌ
+		if isVowelSign(prev) && !(prev == '\u09C7' && (r == '\u09BE' || r == '\u09D7')) {
+			return KindStackedVowelSign
+		}
+		if !isConsonant(prev) && prev != nukta {
+			return KindOrphanVowelSign
+		}
+	case r == hasanta:
+		if prev == hasanta {
+			return KindDoubledHasanta
+		}
+		if !isConsonant(prev) && prev != nukta {
+			return KindOrphanSign
+		}
+	case r == nukta:
+		if !isConsonant(prev) {
+			return KindOrphanSign
+		}
+	case r == candrabindu || r == anusvara || r == visarga:
+		// These follow a consonant, a vowel or a vowel sign
+		if !isConsonant(prev) && !isIndependentVowel(prev) && !isVowelSign(prev) &&
+			prev != nukta && prev != candrabindu {
+			return KindOrphanSign
+		}
+	}
+	return ""
+}
+
+func isConsonant(r rune) bool {
+	return (r >= 'ক' && r <= 'হ') || (r >= 'ড়' && r <= 'য়') || r == 'ৰ' || r == 'ৱ'
+}
+
+func isIndependentVowel(r rune) bool {
+	return (r >= 'অ' && r <= 'ঔ') || r == 'ৠ' || r == 'ৡ'
+}
+
+func isVowelSign(r rune) bool {
+	return (r >= '\u09BE' && r <= '\u09CC') || r == '\u09D7' || r == '\u09E2' || r == '\u09E3'
+}
+
+func newIssue(kind, text string, offset int) Issue {
+	return Issue{Kind: kind, Offset: offset, Context: context(text, offset, 20)}
+}
+
+// context returns up to width runes on each side of offset
+func context(text string, offset, width int) string {
+	before := []rune(text[:offset])
+	after := []rune(text[offset:])
+	if len(before) > width {
+		before = before[len(before)-width:]
+	}
+	if len(after) > width {
+		after = after[:width]
+	}
+	return strings.ReplaceAll(string(before)+string(after), "\n", " ")
+}
+
+// Report aggregates issues over a corpus and keeps a few samples of each kind
+type Report struct {
+	Counts         map[string]int
+	Samples        map[string][]string
+	SamplesPerKind int
+}
+
+// NewReport creates a Report that keeps up to samplesPerKind samples per kind
+func NewReport(samplesPerKind int) *Report {
+	return &Report{
+		Counts:         make(map[string]int),
+		Samples:        make(map[string][]string),
+		SamplesPerKind: samplesPerKind,
+	}
+}
+
+// Add records issues in the report
+func (r *Report) Add(issues []Issue) {
+	for _, issue := range issues {
+		r.Counts[issue.Kind]++
+		if len(r.Samples[issue.Kind]) < r.SamplesPerKind {
+			r.Samples[issue.Kind] = append(r.Samples[issue.Kind], issue.Context)
+		}
+	}
+}
+
+// String formats the counts per kind
+func (r *Report) String() string {
+	kinds := make([]string, 0, len(r.Counts))
+	for kind := range r.Counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	var b strings.Builder
+	for _, kind := range kinds {
+		fmt.Fprintf(&b, "%d %s\n", r.Counts[kind], kind)
+	}
+	return b.String()
+}
+
+// WriteSamples writes "kind<TAB>context" lines for review
+func (r *Report) WriteSamples(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	kinds := make([]string, 0, len(r.Samples))
+	for kind := range r.Samples {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		for _, sample := range r.Samples[kind] {
+			if _, err := fmt.Fprintf(f, "%s\t%s\n", kind, strings.ReplaceAll(sample, "\t", " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
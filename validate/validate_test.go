@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		text, want string
+		kinds      []string
+	}{
+		{"আমার", "আমার", nil},
+		{"বাংলাদেশ () দেশ", "বাংলাদেশ দেশ", []string{KindEmptyBrackets}},
+		{"ক ্খ", "ক খ", []string{KindOrphanSign}},
+		{"কিি", "কি", []string{KindStackedVowelSign}},
+		{"ক্্ষ", "ক্ষ", []string{KindDoubledHasanta}},
+		{"কো কো", "কো কো", nil},
+		// আ typed as অ + া is flagged but kept for canonicalization
+		{"অামার", "অামার", []string{KindSplitVowel}},
+	}
+	for _, tt := range tests {
+		got, issues := Repair(tt.text)
+		if got != tt.want {
+			t.Errorf("Repair(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if len(issues) != len(tt.kinds) {
+			t.Errorf("Repair(%q) found %d issues, want %d", tt.text, len(issues), len(tt.kinds))
+			continue
+		}
+		for i, issue := range issues {
+			if issue.Kind != tt.kinds[i] {
+				t.Errorf("Repair(%q) issue %d is %s, want %s", tt.text, i, issue.Kind, tt.kinds[i])
+			}
+		}
+	}
+}
+
+func TestRepairThenCanonicalizeComposesSplitVowel(t *testing.T) {
+	repaired, _ := Repair("অামার বই")
+	if got := normalize.Canonicalize(repaired, nil); got != "আমার বই" {
+		t.Errorf("got %q, want %q", got, "আমার বই")
+	}
+}
@@ -0,0 +1,109 @@
+// Package variant tells Bangla and Assamese text apart. Both are written in
+// the Bengali script, but Assamese uses ৰ and ৱ where Bangla uses র and ব,
+// and has its own function words. The filter keeps the target language and
+// flags or removes tokens and sentences of the other one.
+package variant
+
+import (
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/normalize"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/segmenter"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+)
+
+// Language codes
+const (
+	Bangla   = "bn"
+	Assamese = "as"
+)
+
+// Common Assamese words that contain neither ৰ nor ৱ
+var assameseWords = map[string]bool{
+	"বুলি": true, "লগত": true, "তেওঁ": true, "তেওঁৰ": true, "সৈতে": true,
+	"আছিল": true, "হৈছে": true, "গৈছে": true, "মাজত": true, "কিয়": true,
+	"এইবোৰ": true, "সেইবোৰ": true, "নহয়": true, "কেতিয়া": true,
+}
+
+// TokenLanguage returns the language a word is specific to, or "" if the
+// word could be either
+func TokenLanguage(word string) string {
+	if strings.ContainsAny(word, "ৰৱ") || assameseWords[word] {
+		return Assamese
+	}
+	if strings.ContainsRune(word, 'র') {
+		return Bangla
+	}
+	return ""
+}
+
+// Filter keeps text in one language. Sentences with at least MinMarkers
+// words of the other language, and no fewer than words of the target
+// language, are treated as foreign.
+type Filter struct {
+	Lang       string // Target language, Bangla or Assamese
+	Remove     bool   // Remove foreign text instead of only counting it
+	MinMarkers int
+
+	SentencesFound int // Foreign sentences seen
+	TokensFound    int // Foreign words seen in otherwise kept sentences
+}
+
+// NewFilter creates a filter for the target language
+func NewFilter(lang string, remove bool) *Filter {
+	return &Filter{Lang: lang, Remove: remove, MinMarkers: 2}
+}
+
+// Apply returns text with foreign sentences and words removed, or unchanged
+// if the filter only counts
+func (f *Filter) Apply(text string) string {
+	// Canonicalize so that the word list matches decomposed য় as well
+	canonical := normalize.Canonicalize(text, nil)
+	// Removed sentences are cut out, line breaks and spacing stay as they were
+	filtered := segmenter.Replace(canonical, segmenter.Spans(canonical), func(_ int, sentence string) string {
+		native, foreign := 0, 0
+		for _, word := range tokenizer.Words(sentence) {
+			switch TokenLanguage(word) {
+			case "":
+			case f.Lang:
+				native++
+			default:
+				foreign++
+			}
+		}
+		if foreign >= f.MinMarkers && foreign >= native {
+			f.SentencesFound++
+			if f.Remove {
+				return ""
+			}
+		} else if foreign > 0 {
+			f.TokensFound += foreign
+			if f.Remove {
+				return f.removeForeignWords(sentence)
+			}
+		}
+		return sentence
+	})
+	if !f.Remove {
+		return text
+	}
+	return filtered
+}
+
+// removeForeignWords drops whitespace-separated fields holding a foreign word
+func (f *Filter) removeForeignWords(sentence string) string {
+	var fields []string
+	for _, field := range strings.Fields(sentence) {
+		foreign := false
+		for _, word := range tokenizer.Words(field) {
+			if lang := TokenLanguage(word); lang != "" && lang != f.Lang {
+				foreign = true
+				break
+			}
+		}
+		if !foreign {
+			fields = append(fields, field)
+		}
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,140 @@
+// Package vocab exports word counts as the vocabulary files of embedding
+// trainers: word2vec (-read-vocab), fastText and GloVe (vocab_count). Words
+// below a minimum count are left out and the vocabulary can be capped to the
+// most frequent words; the tokens of the words left out are counted as <unk>.
+package vocab
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/wordcount"
+)
+
+// Formats
+const (
+	Word2Vec = "word2vec"
+	FastText = "fasttext"
+	GloVe    = "glove"
+)
+
+// Formats lists the formats, the default first
+var Formats = []string{Word2Vec, FastText, GloVe}
+
+// Special tokens
+const (
+	EOS     = "</s>"  // End of a line, which word2vec and fastText read as a token
+	Unknown = "<unk>" // The words left out of the vocabulary
+)
+
+// DefaultSpecial returns the special tokens a trainer expects in its
+// vocabulary. GloVe adds <unk> itself and rejects it in the vocabulary.
+func DefaultSpecial(format string) []string {
+	switch format {
+	case Word2Vec, FastText:
+		return []string{EOS}
+	}
+	return nil
+}
+
+// ValidateFormat checks a format name
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown vocabulary format %q, expected %s", format, strings.Join(Formats, ", "))
+}
+
+// Collector keeps the words of a stream of counts that make the vocabulary
+type Collector struct {
+	minCount int
+	top      *wordcount.Top // With a maximum size
+	words    []wordcount.WordCount
+	Tokens   int64 // Tokens of all words offered
+}
+
+// NewCollector creates a Collector for the words counted at least minCount
+// times, at most maxSize of them when maxSize > 0
+func NewCollector(minCount, maxSize int) *Collector {
+	c := &Collector{minCount: minCount}
+	if maxSize > 0 {
+		c.top = wordcount.NewTop(maxSize)
+	}
+	return c
+}
+
+// Add offers a word with its total count
+func (c *Collector) Add(word string, count int) {
+	c.Tokens += int64(count)
+	if count < c.minCount {
+		return
+	}
+	if c.top != nil {
+		c.top.Add(word, count)
+		return
+	}
+	c.words = append(c.words, wordcount.WordCount{Word: word, Count: count})
+}
+
+// Words returns the vocabulary, most frequent first
+func (c *Collector) Words() []wordcount.WordCount {
+	if c.top != nil {
+		return c.top.Sorted()
+	}
+	sort.Slice(c.words, func(i, j int) bool {
+		return wordcount.Less(c.words[i], c.words[j])
+	})
+	return c.words
+}
+
+// Write writes a vocabulary: the special tokens first, then the words. Each
+// line is "word count", with fastText's entry type appended for fastText.
+func Write(w io.Writer, format string, special, words []wordcount.WordCount) error {
+	writer := bufio.NewWriter(w)
+	for _, list := range [][]wordcount.WordCount{special, words} {
+		for _, wc := range list {
+			var err error
+			if format == FastText {
+				_, err = fmt.Fprintf(writer, "%s %d word\n", wc.Word, wc.Count)
+			} else {
+				_, err = fmt.Fprintf(writer, "%s %d\n", wc.Word, wc.Count)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Flush()
+}
+
+// KeepProbability is the probability that word2vec and fastText keep a token
+// of a word when subsampling frequent words with threshold sample: with f its
+// share of the tokens, sqrt(sample/f) + sample/f, at most 1.
+func KeepProbability(count, total int64, sample float64) float64 {
+	if count <= 0 || total <= 0 || sample <= 0 {
+		return 1
+	}
+	r := sample / (float64(count) / float64(total))
+	return math.Min(math.Sqrt(r)+r, 1)
+}
+
+// WriteSubsampling writes "word\tcount\tfrequency\tkeep_probability" lines
+// for the vocabulary, with total the number of tokens the trainer sees
+func WriteSubsampling(w io.Writer, words []wordcount.WordCount, total int64, sample float64) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintln(writer, "word\tcount\tfrequency\tkeep_probability")
+	for _, wc := range words {
+		frequency := float64(wc.Count) / float64(total)
+		keep := KeepProbability(int64(wc.Count), total, sample)
+		if _, err := fmt.Fprintf(writer, "%s\t%d\t%.3g\t%.4f\n", wc.Word, wc.Count, frequency, keep); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
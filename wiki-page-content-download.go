@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -11,19 +12,49 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
+
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/numerals"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/profile"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/quality"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/scrub"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/sections"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/tokenizer"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/translit"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/validate"
+	"github.com/Rajan-sust/Wiki-Corpus-Builder/variant"
 )
 
 type WikiResponse struct {
 	Query struct {
 		Pages map[string]struct {
-			Title   string `json:"title"`
-			Extract string `json:"extract"`
+			Title     string            `json:"title"`
+			Extract   string            `json:"extract"`
+			PageProps map[string]string `json:"pageprops"`
 		} `json:"pages"`
 	} `json:"query"`
 }
 
+// Document is a cleaned page as written to the output file
+type Document struct {
+	Title     string             `json:"title"`
+	Text      string             `json:"text"`
+	Sentences []string           `json:"sentences,omitempty"`
+	Sections  []sections.Section `json:"sections,omitempty"`
+}
+
+// FetchOptions controls how a fetched page is filtered and cleaned
+type FetchOptions struct {
+	Profile         *profile.Profile
+	Numbers         *numerals.Policy
+	Variant         *variant.Filter // Flags or removes text of the other script variant when not nil
+	Filters         quality.Config
+	DropSections    []string
+	SentencePerLine bool
+	Repairs         *validate.Report // Repairs malformed text when not nil
+	Scrub           scrub.Counts     // Replaces emails, phone numbers and URLs with placeholders when not nil
+}
+
 type LoginTokenResponse struct {
 	Query struct {
 		Tokens struct {
@@ -43,12 +74,76 @@ func main() {
 	outputFile := flag.String("output", "", "Output file to save extracts")
 	username := flag.String("username", "", "Wikipedia bot username")
 	password := flag.String("password", "", "Wikipedia bot password")
+	lang := flag.String("lang", "bn", "Wikipedia language code: "+strings.Join(profile.Codes(), ", "))
+	scriptVariant := flag.String("script-variant", "", "Assamese/Bangla text not in --lang: flag, remove, or empty to skip")
+	filters := quality.DefaultConfig()
+	flag.IntVar(&filters.MinWords, "min-words", filters.MinWords, "Drop pages with fewer words after cleaning (0 disables)")
+	flag.Float64Var(&filters.MinBanglaRatio, "min-bangla-ratio", filters.MinBanglaRatio, "Drop pages with a lower share of Bangla letters (0 disables)")
+	flag.BoolVar(&filters.DropDisambiguation, "drop-disambiguation", filters.DropDisambiguation, "Drop disambiguation pages")
+	flag.BoolVar(&filters.DropLists, "drop-lists", filters.DropLists, "Drop list pages")
+	flag.Float64Var(&filters.ListLineRatio, "list-ratio", filters.ListLineRatio, "Share of short lines above which a page counts as a list (0 only checks titles)")
+	format := flag.String("format", "text", "Output format: text (one page per line) or jsonl")
+	keepSections := flag.Bool("keep-sections", false, "Include section titles and texts in jsonl output")
+	sectionBlocklist := flag.String("drop-sections", strings.Join(sections.DefaultBlocklist, ","), "Comma-separated section headings to drop")
+	sentencePerLine := flag.Bool("sentences", false, "Write one sentence per line, with a blank line between pages")
+	repair := flag.Bool("repair", true, "Repair orphaned signs and empty brackets in extracts")
+	numeralPolicy := flag.String("numerals", numerals.Drop, "Numeral policy: "+strings.Join(numerals.Policies, ", "))
+	latinMode := flag.String("latin", tokenizer.LatinDrop, "Latin-script words in code-mixed text: keep, lower, tag, or empty to drop")
+	romanizedFile := flag.String("romanized", "", "Also write each saved page, romanized with ISO 15919, to this file")
+	scrubPII := flag.Bool("scrub", false, "Replace emails, phone numbers and URLs with <EMAIL>, <PHONE> and <URL>")
 	flag.Parse()
 
 	if *inputFile == "" || *outputFile == "" || *username == "" || *password == "" {
 		fmt.Println("Usage: go run main.go --input titles.txt --output wiki.txt --username=botname --password=botpass")
 		os.Exit(1)
 	}
+	if *format != "text" && *format != "jsonl" {
+		fmt.Printf("Unknown format %q, expected text or jsonl\n", *format)
+		os.Exit(1)
+	}
+	langProfile, err := profile.Get(*lang)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	filters.Script = langProfile.Script
+	tokenOpts := tokenizer.Options{Latin: *latinMode}
+	if *scrubPII {
+		tokenOpts.Special = scrub.Tokens
+	}
+	numbers, err := numerals.New(*numeralPolicy, langProfile, tokenOpts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := FetchOptions{Profile: langProfile, Numbers: numbers, Filters: filters, SentencePerLine: *sentencePerLine}
+	if *scrubPII {
+		opts.Scrub = scrub.Counts{}
+	}
+	switch *scriptVariant {
+	case "":
+	case "flag", "remove":
+		if *lang != variant.Bangla && *lang != variant.Assamese {
+			fmt.Printf("Script-variant filtering needs --lang=bn or --lang=as\n")
+			os.Exit(1)
+		}
+		opts.Variant = variant.NewFilter(*lang, *scriptVariant == "remove")
+	default:
+		fmt.Printf("Unknown script-variant mode %q, expected flag or remove\n", *scriptVariant)
+		os.Exit(1)
+	}
+	if *romanizedFile != "" && *lang != variant.Bangla && *lang != variant.Assamese {
+		fmt.Printf("Romanization needs --lang=bn or --lang=as\n")
+		os.Exit(1)
+	}
+	if *repair {
+		opts.Repairs = validate.NewReport(0)
+	}
+	for _, heading := range strings.Split(*sectionBlocklist, ",") {
+		if heading = strings.TrimSpace(heading); heading != "" {
+			opts.DropSections = append(opts.DropSections, heading)
+		}
+	}
 
 	// Create output directory
 	outputDir := filepath.Dir(*outputFile)
@@ -72,6 +167,16 @@ func main() {
 	}
 	defer outputHandle.Close()
 
+	var romanizedHandle *os.File
+	if *romanizedFile != "" {
+		romanizedHandle, err = os.OpenFile(*romanizedFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Printf("Error opening romanized file: %v\n", err)
+			os.Exit(1)
+		}
+		defer romanizedHandle.Close()
+	}
+
 	// Create HTTP client for session
 	jar, _ := cookiejar.New(nil)
 
@@ -80,19 +185,21 @@ func main() {
 	}
 
 	// Perform login
-	loginToken, err := getLoginToken(client)
+	loginToken, err := getLoginToken(client, *lang)
 	if err != nil {
 		fmt.Printf("Error getting login token: %v\n", err)
 		os.Exit(1)
 	}
 
-	err = performLogin(client, *username, *password, loginToken)
+	err = performLogin(client, *lang, *username, *password, loginToken)
 	if err != nil {
 		fmt.Printf("Login failed: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Process titles
+	dropped := quality.Stats{}
+	saved := 0
 	scanner := bufio.NewScanner(inputHandle)
 	for scanner.Scan() {
 		title := strings.TrimSpace(scanner.Text())
@@ -100,16 +207,32 @@ func main() {
 			continue
 		}
 
-		extract, err := fetchWikipediaExtract(client, title)
+		doc, err := fetchWikipediaExtract(client, title, opts)
+		var dropErr *quality.DroppedError
+		if errors.As(err, &dropErr) {
+			dropped[dropErr.Reason]++
+			fmt.Printf("Page `%s` dropped: %s\n", title, dropErr.Reason)
+			continue
+		}
 		if err != nil {
 			fmt.Printf("Error fetching extract for %s: %v\n", title, err)
 			continue
 		}
 
-		_, err = outputHandle.WriteString(fmt.Sprintf("%s\n", extract))
+		line, err := formatDocument(doc, *format, *keepSections)
+		if err == nil {
+			_, err = outputHandle.WriteString(line)
+		}
+		if err == nil && romanizedHandle != nil {
+			line, err = formatDocument(romanizeDocument(doc), *format, *keepSections)
+			if err == nil {
+				_, err = romanizedHandle.WriteString(line)
+			}
+		}
 		if err != nil {
 			fmt.Printf("Error writing to output file: %v\n", err)
 		} else {
+			saved++
 			fmt.Printf("Page `%s` successfully fetched\n", title)
 		}
 	}
@@ -119,12 +242,59 @@ func main() {
 		os.Exit(1)
 	}
 
+	fmt.Printf("Saved %d pages, dropped: %s\n", saved, dropped)
+	if opts.Repairs != nil {
+		fmt.Printf("Repaired malformed text:\n%s", opts.Repairs)
+	}
+	if opts.Scrub != nil {
+		fmt.Printf("Scrubbed: %s\n", opts.Scrub)
+	}
+	if opts.Variant != nil {
+		fmt.Printf("Script variant not %s: %d sentences, %d words\n", *lang, opts.Variant.SentencesFound, opts.Variant.TokensFound)
+	}
 	fmt.Println("Wikipedia extracts saved successfully.")
 }
 
-func getLoginToken(client *http.Client) (string, error) {
+// formatDocument renders a page as one output line
+func formatDocument(doc Document, format string, keepSections bool) (string, error) {
+	if format == "text" {
+		if len(doc.Sentences) > 0 {
+			return strings.Join(doc.Sentences, "\n") + "\n\n", nil
+		}
+		return doc.Text + "\n", nil
+	}
+	if !keepSections {
+		doc.Sections = nil
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// romanizeDocument returns a copy of the page with its text romanized, so
+// that the romanized file lines up with the output file
+func romanizeDocument(doc Document) Document {
+	doc.Text = translit.ToISO15919(doc.Text)
+	sentences := make([]string, len(doc.Sentences))
+	for i, sentence := range doc.Sentences {
+		sentences[i] = translit.ToISO15919(sentence)
+	}
+	doc.Sentences = sentences
+	secs := make([]sections.Section, len(doc.Sections))
+	for i, sec := range doc.Sections {
+		sec.Title = translit.ToISO15919(sec.Title)
+		sec.Text = translit.ToISO15919(sec.Text)
+		secs[i] = sec
+	}
+	doc.Sections = secs
+	return doc
+}
+
+func getLoginToken(client *http.Client, lang string) (string, error) {
 	// API endpoint
-	apiURL := "https://bn.wikipedia.org/w/api.php"
+	apiURL := fmt.Sprintf("https://%s.wikipedia.org/w/api.php", lang)
 
 	// Prepare token request
 	req, err := http.NewRequest("GET", apiURL, nil)
@@ -162,9 +332,9 @@ func getLoginToken(client *http.Client) (string, error) {
 	return tokenResp.Query.Tokens.LoginToken, nil
 }
 
-func performLogin(client *http.Client, username, password, loginToken string) error {
+func performLogin(client *http.Client, lang, username, password, loginToken string) error {
 	// API endpoint
-	apiURL := "https://bn.wikipedia.org/w/api.php"
+	apiURL := fmt.Sprintf("https://%s.wikipedia.org/w/api.php", lang)
 
 	// Prepare login data
 	data := url.Values{}
@@ -208,60 +378,93 @@ func performLogin(client *http.Client, username, password, loginToken string) er
 	return nil
 }
 
-// removeNukta replaces nuktas in Bangla text with their corresponding replacements
-func removeNukta(banglaText string) string {
-	nuktaReplacements := map[string]string{
-		"\u09A1\u09BC": "\u09DC", // \u09DC is 2524 in decimal
-		"\u09A2\u09BC": "\u09DD", // \u09DD is 2525 in decimal
-		"\u09AF\u09BC": "\u09DF", // \u09DF is 2527 in decimal
-	}
-
-	for nuktaChar, replacement := range nuktaReplacements {
-		banglaText = strings.ReplaceAll(banglaText, nuktaChar, replacement)
-	}
-
-	return banglaText
-}
-
-func preprocessText(input string) string {
-	// Remove nuktas
-	input = removeNukta(input)
-	// Define the regex for Bangla words
-	banglaWordRegex := regexp.MustCompile(`[\x{0980}-\x{09E5}\x{09F0}-\x{09FF}]+`)
-	// Find all matches in the input string
-	banglaWords := banglaWordRegex.FindAllString(input, -1)
-	return strings.Join(banglaWords, " ")
+func preprocessText(input string, lang *profile.Profile, numbers *numerals.Policy) string {
+	// Canonicalize nukta letters, vowel signs, khanda ta and joiners
+	input = lang.Normalize(input)
+	// Split into words, keeping conjuncts and compounds intact, and apply
+	// the numeral policy
+	words := numbers.Words(input)
+	return strings.Join(words, " ")
 
 }
 
-func fetchWikipediaExtract(client *http.Client, title string) (string, error) {
-	apiURL := fmt.Sprintf("https://bn.wikipedia.org/w/api.php?format=json&action=query&prop=extracts&explaintext&redirects=1&titles=%s", url.QueryEscape(title))
+func fetchWikipediaExtract(client *http.Client, title string, opts FetchOptions) (Document, error) {
+	apiURL := fmt.Sprintf("https://%s.wikipedia.org/w/api.php?format=json&action=query&prop=extracts|pageprops&ppprop=disambiguation&explaintext&exsectionformat=wiki&redirects=1&titles=%s", opts.Profile.Code, url.QueryEscape(title))
 
 	resp, err := client.Get(apiURL)
 	if err != nil {
-		return "", err
+		return Document{}, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return Document{}, err
 	}
 
 	var wikiResp WikiResponse
 	if err := json.Unmarshal(body, &wikiResp); err != nil {
-		return "", err
+		return Document{}, err
 	}
 
-	var extract string
+	var doc Document
 	for _, page := range wikiResp.Query.Pages {
-		extract = preprocessText(page.Extract)
+		if opts.Repairs != nil {
+			var issues []validate.Issue
+			page.Extract, issues = validate.Repair(page.Extract)
+			opts.Repairs.Add(issues)
+		}
+		if opts.Scrub != nil {
+			page.Extract = scrub.Scrub(page.Extract, opts.Scrub)
+		}
+
+		// Split into sections and drop references, external links, etc.
+		kept := sections.Drop(sections.Parse(page.Extract), opts.DropSections)
+
+		var rawParts, cleanParts []string
+		for _, s := range kept {
+			if opts.Variant != nil {
+				s.Text = opts.Variant.Apply(s.Text)
+			}
+			rawParts = append(rawParts, s.Text)
+			if opts.SentencePerLine {
+				// Segment before cleaning, since preprocessText drops the danda
+				for _, sentence := range opts.Profile.Sentences(s.Text) {
+					if cleaned := preprocessText(sentence, opts.Profile, opts.Numbers); cleaned != "" {
+						doc.Sentences = append(doc.Sentences, cleaned)
+					}
+				}
+			}
+			s.Title = preprocessText(s.Title, opts.Profile, opts.Numbers)
+			s.Text = preprocessText(s.Text, opts.Profile, opts.Numbers)
+			if s.Text == "" {
+				continue
+			}
+			cleanParts = append(cleanParts, s.Text)
+			doc.Sections = append(doc.Sections, s)
+		}
+		doc.Title = page.Title
+		doc.Text = strings.Join(cleanParts, " ")
+		if doc.Text == "" {
+			break
+		}
+
+		_, disambiguation := page.PageProps["disambiguation"]
+		err := opts.Filters.Check(quality.Page{
+			Title:          page.Title,
+			Extract:        strings.Join(rawParts, "\n"),
+			Cleaned:        doc.Text,
+			Disambiguation: disambiguation,
+		})
+		if err != nil {
+			return Document{}, err
+		}
 		break
 	}
 
-	if extract == "" {
-		return "", fmt.Errorf("no extract found for title: %s", title)
+	if doc.Text == "" {
+		return Document{}, fmt.Errorf("no extract found for title: %s", title)
 	}
 
-	return extract, nil
+	return doc, nil
 }
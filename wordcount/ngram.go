@@ -0,0 +1,65 @@
+package wordcount
+
+import (
+	"strings"
+	"unsafe"
+)
+
+// NGramSeparator joins the words of an n-gram. Tokenizers never emit words
+// with spaces, so the order of an n-gram can be read back from it.
+const NGramSeparator = " "
+
+// Filter tokenizes a line like Tokenize, but also passes on the tokens that
+// are left out of the counts, such as dropped numbers, with keep false
+type Filter func(line string, fn func(token string, keep bool))
+
+// Kept turns a Filter into a Tokenize that passes on only the kept tokens
+func Kept(tokenize Filter) Tokenize {
+	return func(line string, add func(word string)) {
+		tokenize(line, func(token string, keep bool) {
+			if keep {
+				add(token)
+			}
+		})
+	}
+}
+
+// NGrams turns a filtering tokenizer into one that emits the n-grams of order
+// 1 to maxOrder of each line. split cuts the line into sentences first, so
+// that no n-gram spans a sentence boundary, and a token left out ends the
+// n-grams too, so that words it separated are never joined. The n-grams
+// passed to add are only valid during the call, like the words of a mapped
+// file.
+func NGrams(tokenize Filter, split func(line string) []string, maxOrder int) Tokenize {
+	return func(line string, add func(ngram string)) {
+		var words []string
+		var buf []byte
+		flush := func() {
+			for i, word := range words {
+				add(word)
+				buf = append(buf[:0], word...)
+				for n := 2; n <= maxOrder && i+n <= len(words); n++ {
+					buf = append(buf, NGramSeparator...)
+					buf = append(buf, words[i+n-1]...)
+					add(unsafe.String(&buf[0], len(buf)))
+				}
+			}
+			words = words[:0]
+		}
+		for _, sentence := range split(line) {
+			tokenize(sentence, func(token string, keep bool) {
+				if !keep {
+					flush()
+					return
+				}
+				words = append(words, token)
+			})
+			flush()
+		}
+	}
+}
+
+// Order returns the number of words in an n-gram
+func Order(ngram string) int {
+	return strings.Count(ngram, NGramSeparator) + 1
+}
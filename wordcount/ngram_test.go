@@ -0,0 +1,32 @@
+package wordcount
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNGramsStopAtLeftOutTokens(t *testing.T) {
+	// Digits and "এবং" are left out, "।" ends a sentence
+	tokens := func(line string, fn func(token string, keep bool)) {
+		for _, token := range strings.Fields(line) {
+			fn(token, token != "এবং" && !strings.ContainsAny(token, "০১২৩৪৫৬৭৮৯"))
+		}
+	}
+	sentences := func(line string) []string {
+		return strings.SplitAfter(line, "।")
+	}
+	var got []string
+	NGrams(tokens, sentences, 3)("রহিম ১৯৭১ সালে ঢাকা গেল। রহিম এবং করিম", func(ngram string) {
+		got = append(got, strings.Clone(ngram)) // Only valid during the call
+	})
+	want := []string{
+		"রহিম",
+		"সালে", "সালে ঢাকা", "সালে ঢাকা গেল।", "ঢাকা", "ঢাকা গেল।", "গেল।",
+		"রহিম",
+		"করিম",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
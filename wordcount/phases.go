@@ -0,0 +1,98 @@
+package wordcount
+
+import (
+	"fmt"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"time"
+)
+
+// heapMetric is the memory occupied by live and not yet swept heap objects
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// Phase is the duration and peak heap size of one step of a run
+type Phase struct {
+	Name     string
+	Duration time.Duration
+	PeakHeap uint64
+}
+
+// Phases times the steps of a run and samples the heap in the background to
+// find the peak of each step
+type Phases struct {
+	mu      sync.Mutex
+	done    []Phase
+	current Phase
+	start   time.Time
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// StartPhases starts timing the first phase
+func StartPhases(name string) *Phases {
+	p := &Phases{
+		current: Phase{Name: name},
+		start:   time.Now(),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	p.sample()
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.sample()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+func (p *Phases) sample() {
+	s := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(s)
+	heap := s[0].Value.Uint64()
+	p.mu.Lock()
+	p.current.PeakHeap = max(p.current.PeakHeap, heap)
+	p.mu.Unlock()
+}
+
+// Next ends the current phase and starts a new one
+func (p *Phases) Next(name string) {
+	p.sample()
+	p.mu.Lock()
+	p.current.Duration = time.Since(p.start)
+	p.done = append(p.done, p.current)
+	p.current = Phase{Name: name}
+	p.start = time.Now()
+	p.mu.Unlock()
+	p.sample()
+}
+
+// Stop ends the last phase and returns all of them
+func (p *Phases) Stop() []Phase {
+	p.Next("")
+	close(p.stop)
+	<-p.stopped
+	return p.done
+}
+
+// FormatPhases formats phases as a table for the run report
+func FormatPhases(phases []Phase) string {
+	var b strings.Builder
+	var total time.Duration
+	var peak uint64
+	for _, ph := range phases {
+		fmt.Fprintf(&b, "%-8s %10s  peak heap %8.1f MB\n", ph.Name, ph.Duration.Round(time.Millisecond), float64(ph.PeakHeap)/(1<<20))
+		total += ph.Duration
+		peak = max(peak, ph.PeakHeap)
+	}
+	fmt.Fprintf(&b, "%-8s %10s  peak heap %8.1f MB\n", "total", total.Round(time.Millisecond), float64(peak)/(1<<20))
+	return b.String()
+}
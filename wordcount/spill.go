@@ -0,0 +1,334 @@
+package wordcount
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// entryOverhead estimates the memory of a count map entry besides the word:
+// string header, counter, and the map's own bookkeeping
+const entryOverhead = 80
+
+// maxMergeFanIn bounds the runs merged at once, and so the open files
+const maxMergeFanIn = 128
+
+// Runs are sorted "word\tcount" files written by CountToDisk, or count files
+// written with CountWriter. Every word appears at most once per run.
+type Runs struct {
+	dir   string
+	paths []string
+}
+
+// CountToDisk counts like Count, but keeps each worker under its share of
+// budget bytes: when a worker's counts reach it, they are written to a sorted
+// run file in a new directory under dir and the worker starts over.
+func CountToDisk(data []byte, tokenize Tokenize, opts Options, budget int64, dir string) (*Runs, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	tmp, err := os.MkdirTemp(dir, "wordcount-")
+	if err != nil {
+		return nil, err
+	}
+	runs := &Runs{dir: tmp}
+	chunks := make(chan []byte, workers)
+	go func() {
+		for _, chunk := range Chunks(data, ChunkSizeFor(len(data), workers)) {
+			chunks <- chunk
+		}
+		close(chunks)
+	}()
+
+	var mu sync.Mutex
+	var firstErr error
+	spill := func(counts map[string]*int) {
+		path, err := writeRun(tmp, counts)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err == nil {
+			runs.paths = append(runs.paths, path)
+		}
+	}
+
+	workerBudget := budget / int64(workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			counts := make(map[string]*int)
+			var used int64
+			add := func(word string) {
+				if n := counts[word]; n != nil {
+					*n++
+					return
+				}
+				n := 1
+				counts[strings.Clone(word)] = &n
+				used += int64(len(word)) + entryOverhead
+				if used >= workerBudget {
+					spill(counts)
+					counts, used = make(map[string]*int), 0
+				}
+			}
+			for chunk := range chunks {
+				EachLine(chunk, func(line string) {
+					tokenize(line, add)
+				})
+				if opts.Progress != nil {
+					opts.Progress(int64(len(chunk)))
+				}
+			}
+			if len(counts) > 0 {
+				spill(counts)
+			}
+			if opts.Done != nil {
+				opts.Done(worker)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		runs.Close()
+		return nil, firstErr
+	}
+	return runs, nil
+}
+
+// writeRun writes counts sorted by word to a new file in dir
+func writeRun(dir string, counts map[string]*int) (string, error) {
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	f, err := os.CreateTemp(dir, "run-*.tsv")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, 64<<10)
+	for _, word := range words {
+		if _, err := fmt.Fprintf(w, "%s\t%d\n", word, *counts[word]); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return f.Name(), f.Close()
+}
+
+// Len returns the number of run files
+func (r *Runs) Len() int {
+	return len(r.paths)
+}
+
+// Each merges the runs and calls fn once per word with its total count, in
+// word order. Runs are first merged in rounds of maxMergeFanIn files.
+func (r *Runs) Each(fn func(word string, count int)) error {
+	for len(r.paths) > maxMergeFanIn {
+		var merged []string
+		for start := 0; start < len(r.paths); start += maxMergeFanIn {
+			end := min(start+maxMergeFanIn, len(r.paths))
+			path, err := r.mergeToFile(r.paths[start:end])
+			if err != nil {
+				return err
+			}
+			merged = append(merged, path)
+		}
+		r.paths = merged
+	}
+	return mergeRuns(r.paths, fn)
+}
+
+// mergeToFile merges runs into a new run and removes them
+func (r *Runs) mergeToFile(paths []string) (string, error) {
+	f, err := os.CreateTemp(r.dir, "merge-*.tsv")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, 64<<10)
+	var werr error
+	err = mergeRuns(paths, func(word string, count int) {
+		if werr == nil {
+			_, werr = fmt.Fprintf(w, "%s\t%d\n", word, count)
+		}
+	})
+	if err == nil {
+		err = werr
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		return "", err
+	}
+	// Remove the runs this merge made, never the count files it was given
+	for _, path := range paths {
+		if filepath.Dir(path) == r.dir {
+			os.Remove(path)
+		}
+	}
+	return f.Name(), f.Close()
+}
+
+// Close removes the run files
+func (r *Runs) Close() error {
+	return os.RemoveAll(r.dir)
+}
+
+// runReader reads one run
+type runReader struct {
+	r     *bufio.Reader
+	f     *os.File
+	word  string
+	count int
+}
+
+func (rr *runReader) next() (bool, error) {
+	line, err := rr.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return false, nil
+	}
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	tab := strings.LastIndexByte(line, '\t')
+	if tab < 0 {
+		return false, fmt.Errorf("%s: malformed line %q", rr.f.Name(), line)
+	}
+	count, err := strconv.Atoi(line[tab+1:])
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", rr.f.Name(), err)
+	}
+	rr.word, rr.count = line[:tab], count
+	return true, nil
+}
+
+// runHeap orders run readers by their current word
+type runHeap []*runReader
+
+func (h runHeap) Len() int            { return len(h) }
+func (h runHeap) Less(i, j int) bool  { return h[i].word < h[j].word }
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*runReader)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// mergeRuns is a k-way merge of sorted runs, summing the counts of a word
+func mergeRuns(paths []string, fn func(word string, count int)) error {
+	h := make(runHeap, 0, len(paths))
+	defer func() {
+		for _, rr := range h {
+			rr.f.Close()
+		}
+	}()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		rr := &runReader{r: bufio.NewReaderSize(f, 64<<10), f: f}
+		ok, err := rr.next()
+		if err != nil {
+			f.Close()
+			return err
+		}
+		if !ok {
+			f.Close()
+			continue
+		}
+		h = append(h, rr)
+	}
+	heap.Init(&h)
+
+	for len(h) > 0 {
+		word, count := h[0].word, 0
+		for len(h) > 0 && h[0].word == word {
+			rr := h[0]
+			count += rr.count
+			ok, err := rr.next()
+			if err != nil {
+				return err
+			}
+			if ok {
+				heap.Fix(&h, 0)
+			} else {
+				rr.f.Close()
+				heap.Pop(&h)
+			}
+		}
+		fn(word, count)
+	}
+	return nil
+}
+
+// MergeCountFiles merges count files and calls fn once per word with its
+// total count, in word order. Only one line per file is held in memory.
+func MergeCountFiles(paths []string, fn func(word string, count int)) error {
+	tmp, err := os.MkdirTemp("", "wordcount-")
+	if err != nil {
+		return err
+	}
+	runs := &Runs{dir: tmp, paths: append([]string(nil), paths...)}
+	defer runs.Close()
+	return runs.Each(fn)
+}
+
+// CountWriter writes a count file: "word\tcount" lines sorted by word, so
+// that count files of separate parts can be merged without loading them
+type CountWriter struct {
+	f    *os.File
+	w    *bufio.Writer
+	last string
+	n    int
+}
+
+// CreateCountFile creates a count file
+func CreateCountFile(path string) (*CountWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &CountWriter{f: f, w: bufio.NewWriterSize(f, 64<<10)}, nil
+}
+
+// Write adds a word, which must sort after the previous one
+func (c *CountWriter) Write(word string, count int) error {
+	if c.n > 0 && word <= c.last {
+		return fmt.Errorf("%s: %q written after %q", c.f.Name(), word, c.last)
+	}
+	c.last = word
+	c.n++
+	_, err := fmt.Fprintf(c.w, "%s\t%d\n", word, count)
+	return err
+}
+
+// Close flushes and closes the file
+func (c *CountWriter) Close() error {
+	err := c.w.Flush()
+	if cerr := c.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
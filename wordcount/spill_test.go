@@ -0,0 +1,93 @@
+package wordcount
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCountToDiskMatchesCount(t *testing.T) {
+	data := testCorpus(5000)
+	want := Count(data, fields, Options{Workers: 3}).Merge(3)
+
+	// A budget of a few words per worker forces many spills and merge passes
+	runs, err := CountToDisk(data, fields, Options{Workers: 3}, 3*1024, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer runs.Close()
+	if runs.Len() <= maxMergeFanIn {
+		t.Fatalf("only %d runs, the budget does not force a multi-pass merge", runs.Len())
+	}
+
+	seen, last := 0, ""
+	err = runs.Each(func(word string, count int) {
+		if seen > 0 && word <= last {
+			t.Errorf("%q after %q", word, last)
+		}
+		if n := want.Get(word); n != count {
+			t.Errorf("%s: got %d, want %d", word, count, n)
+		}
+		seen++
+		last = word
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != want.Len() {
+		t.Errorf("got %d distinct words, want %d", seen, want.Len())
+	}
+}
+
+func TestMergeCountFiles(t *testing.T) {
+	data := testCorpus(5000)
+	want := Count(data, fields, Options{Workers: 2}).Merge(2)
+
+	// Count each half into its own count file, as the merge subcommand expects
+	half := len(Chunks(data, len(data)/2+1)[0])
+	var paths []string
+	for i, part := range [][]byte{data[:half], data[half:]} {
+		path := filepath.Join(t.TempDir(), "part"+strconv.Itoa(i)+".counts")
+		w, err := CreateCountFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		Count(part, fields, Options{Workers: 2}).Merge(2).EachSorted(func(word string, count int) {
+			if err := w.Write(word, count); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	seen := 0
+	err := MergeCountFiles(paths, func(word string, count int) {
+		if n := want.Get(word); n != count {
+			t.Errorf("%s: got %d, want %d", word, count, n)
+		}
+		seen++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != want.Len() {
+		t.Errorf("got %d distinct words, want %d", seen, want.Len())
+	}
+}
+
+func TestCountWriterRejectsUnsorted(t *testing.T) {
+	w, err := CreateCountFile(filepath.Join(t.TempDir(), "unsorted.counts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.Write("খ", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("ক", 1); err == nil {
+		t.Error("a word sorting before the previous one was accepted")
+	}
+}
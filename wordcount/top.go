@@ -0,0 +1,68 @@
+package wordcount
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// WordCount is a word and its count
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// Less orders by count, most frequent first, and then by word
+func Less(a, b WordCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Word < b.Word
+}
+
+// Top keeps the n first words in Less order out of all words added, so the
+// whole vocabulary never has to be sorted
+type Top struct {
+	n     int
+	words topHeap
+}
+
+// NewTop creates a Top for n words
+func NewTop(n int) *Top {
+	return &Top{n: n}
+}
+
+// Add offers a word; each word must be added once
+func (t *Top) Add(word string, count int) {
+	wc := WordCount{word, count}
+	switch {
+	case t.n <= 0:
+	case len(t.words) < t.n:
+		heap.Push(&t.words, wc)
+	case Less(wc, t.words[0]):
+		t.words[0] = wc
+		heap.Fix(&t.words, 0)
+	}
+}
+
+// Sorted returns the kept words in Less order
+func (t *Top) Sorted() []WordCount {
+	sorted := append([]WordCount(nil), t.words...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return Less(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// topHeap has the last word in Less order on top, the first to be replaced
+type topHeap []WordCount
+
+func (h topHeap) Len() int            { return len(h) }
+func (h topHeap) Less(i, j int) bool  { return Less(h[j], h[i]) }
+func (h topHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *topHeap) Push(x interface{}) { *h = append(*h, x.(WordCount)) }
+func (h *topHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
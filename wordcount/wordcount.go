@@ -0,0 +1,342 @@
+// Package wordcount counts the words of a large corpus file in parallel. The
+// file is mapped into memory and cut into byte ranges that end at line
+// boundaries; each worker takes whole chunks, so there is no per-line channel
+// traffic and no limit on line length.
+package wordcount
+
+import (
+	"bytes"
+	"hash/maphash"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// ChunkSize is the largest target size of a chunk. Chunks are much smaller
+// than a worker's share of the file so that fast workers take over the rest.
+const ChunkSize = 16 << 20
+
+// MinChunkSize is the smallest target size of a chunk, below which the
+// channel traffic would outweigh the work
+const MinChunkSize = 1 << 20
+
+// ChunkSizeFor returns the chunk size for size bytes and a number of
+// workers: about four chunks per worker, within MinChunkSize and ChunkSize
+func ChunkSizeFor(size, workers int) int {
+	return min(ChunkSize, max(size/(max(workers, 1)*4), MinChunkSize))
+}
+
+// File is a corpus file mapped into memory
+type File struct {
+	Data  []byte
+	file  *os.File
+	unmap func() error
+}
+
+// Open maps a file into memory
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	data, unmap, err := mapFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &File{Data: data, file: f, unmap: unmap}, nil
+}
+
+// Close unmaps and closes the file. Strings passed to a Tokenize function
+// must not be used afterwards.
+func (f *File) Close() error {
+	err := f.unmap()
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Chunks cuts data into pieces of about size bytes, each ending after a
+// newline or at the end of data
+func Chunks(data []byte, size int) [][]byte {
+	var chunks [][]byte
+	for len(data) > 0 {
+		end := len(data)
+		if size < end {
+			end = size
+			if i := bytes.IndexByte(data[end:], '\n'); i >= 0 {
+				end += i + 1
+			} else {
+				end = len(data)
+			}
+		}
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+	return chunks
+}
+
+// EachLine calls fn for every line of chunk, empty ones included, without the
+// newline. The line points into chunk and is only valid during the call.
+func EachLine(chunk []byte, fn func(line string)) {
+	for len(chunk) > 0 {
+		end := bytes.IndexByte(chunk, '\n')
+		next := end + 1
+		if end < 0 {
+			end, next = len(chunk), len(chunk)
+		}
+		line := chunk[:end]
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		if len(line) > 0 {
+			fn(unsafe.String(&line[0], len(line)))
+		} else {
+			fn("")
+		}
+		chunk = chunk[next:]
+	}
+}
+
+// Tokenize splits a line into words and passes each one to add
+type Tokenize func(line string, add func(word string))
+
+// Options controls a parallel count
+type Options struct {
+	Workers  int
+	Progress func(bytes int64) // Called after each chunk when not nil; must be safe for concurrent use
+	Done     func(worker int)  // Called when a worker has finished when not nil
+}
+
+// Shards is the number of hash partitions of the counts. Each worker counts
+// into its own shards, and the shards are then merged in parallel.
+const Shards = 64
+
+var seed = maphash.MakeSeed()
+
+func shardOf(word string) int {
+	return int(maphash.String(seed, word) % Shards)
+}
+
+// Partial holds the per-worker counts of a Count, before they are merged
+type Partial struct {
+	workers [][Shards]map[string]*int
+}
+
+// Count counts the words of data. Words are interned, so all workers share
+// one copy of each word, and copied out of data, so the counts stay valid
+// after data is unmapped.
+func Count(data []byte, tokenize Tokenize, opts Options) *Partial {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	chunks := make(chan []byte, workers)
+	go func() {
+		for _, chunk := range Chunks(data, ChunkSizeFor(len(data), workers)) {
+			chunks <- chunk
+		}
+		close(chunks)
+	}()
+
+	words := newInterner()
+	partial := &Partial{workers: make([][Shards]map[string]*int, workers)}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			// Counters are updated through pointers: assigning to a map
+			// entry also replaces its key, which would point back into data
+			local := &partial.workers[worker]
+			for s := range local {
+				local[s] = make(map[string]*int)
+			}
+			add := func(word string) {
+				shard := shardOf(word)
+				if n := local[shard][word]; n != nil {
+					*n++
+				} else {
+					n := 1
+					local[shard][words.intern(shard, word)] = &n
+				}
+			}
+			for chunk := range chunks {
+				EachLine(chunk, func(line string) {
+					tokenize(line, add)
+				})
+				if opts.Progress != nil {
+					opts.Progress(int64(len(chunk)))
+				}
+			}
+			if opts.Done != nil {
+				opts.Done(worker)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return partial
+}
+
+// Merge sums the workers' counts shard by shard, with up to workers shards
+// merged at the same time. Worker counts are released as they are merged.
+func (p *Partial) Merge(workers int) Counts {
+	if workers < 1 {
+		workers = 1
+	}
+	counts := make(Counts, Shards)
+	shards := make(chan int, Shards)
+	for s := 0; s < Shards; s++ {
+		shards <- s
+	}
+	close(shards)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s := range shards {
+				size := 0
+				for w := range p.workers {
+					size = max(size, len(p.workers[w][s]))
+				}
+				merged := make(map[string]int, size)
+				for w := range p.workers {
+					for word, n := range p.workers[w][s] {
+						merged[word] += *n
+					}
+					p.workers[w][s] = nil
+				}
+				counts[s] = merged
+			}
+		}()
+	}
+	wg.Wait()
+	return counts
+}
+
+// Counts holds merged word counts, one map per shard
+type Counts []map[string]int
+
+// Len returns the number of distinct words
+func (c Counts) Len() int {
+	n := 0
+	for _, shard := range c {
+		n += len(shard)
+	}
+	return n
+}
+
+// Get returns the count of a word
+func (c Counts) Get(word string) int {
+	return c[shardOf(word)][word]
+}
+
+// Each calls fn for every word and its count, in no particular order
+func (c Counts) Each(fn func(word string, count int)) {
+	for _, shard := range c {
+		for word, count := range shard {
+			fn(word, count)
+		}
+	}
+}
+
+// EachSorted calls fn for every word and its count in word order
+func (c Counts) EachSorted(fn func(word string, count int)) {
+	words := make([]string, 0, c.Len())
+	c.Each(func(word string, count int) {
+		words = append(words, word)
+	})
+	sort.Strings(words)
+	for _, word := range words {
+		fn(word, c.Get(word))
+	}
+}
+
+// interner keeps one copy of every word, sharded like the counts so that
+// workers rarely wait for each other
+type interner struct {
+	shards [Shards]struct {
+		sync.Mutex
+		words map[string]string
+	}
+}
+
+func newInterner() *interner {
+	in := &interner{}
+	for s := range in.shards {
+		in.shards[s].words = make(map[string]string)
+	}
+	return in
+}
+
+// intern returns the shared copy of word, making one if needed
+func (in *interner) intern(shard int, word string) string {
+	sh := &in.shards[shard]
+	sh.Lock()
+	defer sh.Unlock()
+	if w, ok := sh.words[word]; ok {
+		return w
+	}
+	w := strings.Clone(word)
+	sh.words[w] = w
+	return w
+}
+
+// Stream reads r in blocks of about ChunkSize bytes that end at line
+// boundaries and hands them to workers, for input whose size is not known in
+// advance, like stdin. process is called concurrently with the worker number.
+func Stream(r io.Reader, workers int, process func(worker int, block []byte)) error {
+	if workers < 1 {
+		workers = 1
+	}
+	blocks := make(chan []byte, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for block := range blocks {
+				process(worker, block)
+			}
+		}(i)
+	}
+
+	var err error
+	var carry []byte // Start of a line that continues in the next read
+	for err == nil {
+		block := make([]byte, len(carry), len(carry)+ChunkSize)
+		copy(block, carry)
+		var n int
+		n, err = io.ReadFull(r, block[len(carry):cap(block)])
+		block = block[:len(carry)+n]
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = io.EOF
+			carry = nil
+		} else if i := bytes.LastIndexByte(block, '\n'); i >= 0 {
+			carry = append([]byte(nil), block[i+1:]...)
+			block = block[:i+1]
+		} else {
+			carry, block = block, nil // A line longer than the block, keep reading
+		}
+		if len(block) > 0 {
+			blocks <- block
+		}
+	}
+	close(blocks)
+	wg.Wait()
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
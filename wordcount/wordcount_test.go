@@ -0,0 +1,152 @@
+package wordcount
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var corpusFile = flag.String("corpus", "", "Corpus to benchmark the readers on instead of generated text")
+
+// benchWorkers is the number of workers of the benchmarks
+const benchWorkers = 4
+
+// fields splits a line at whitespace
+func fields(line string, add func(word string)) {
+	for _, word := range strings.Fields(line) {
+		add(word)
+	}
+}
+
+// testCorpus returns lines of words with a skewed frequency distribution
+func testCorpus(lines int) []byte {
+	words := []string{"বাংলাদেশ", "দক্ষিণ", "এশিয়ার", "একটি", "স্বাধীন", "রাষ্ট্র", "ও", "এবং", "নদী", "ভাষা"}
+	var b bytes.Buffer
+	for i := 0; i < lines; i++ {
+		for j := 0; j <= i%7; j++ {
+			// Every word is frequent, plus one rare word per line
+			fmt.Fprintf(&b, "%s ", words[(i*j+j)%len(words)])
+		}
+		fmt.Fprintf(&b, "শব্দ%d\n", i%(lines/3+1))
+	}
+	return b.Bytes()
+}
+
+// benchCorpus returns the -corpus file, or generated text of about 19 MB,
+// which makes four chunks per worker
+func benchCorpus(b *testing.B) []byte {
+	data := testCorpus(200000)
+	if *corpusFile != "" {
+		var err error
+		if data, err = os.ReadFile(*corpusFile); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if chunks := len(Chunks(data, ChunkSizeFor(len(data), benchWorkers))); chunks < 2*benchWorkers {
+		b.Logf("only %d chunks for %d workers", chunks, benchWorkers)
+	}
+	return data
+}
+
+// countWithScanner is the original pipeline of the word counter: one
+// bufio.Scanner feeding lines to the workers over a channel
+func countWithScanner(r io.Reader, workers int, tokenize Tokenize) (map[string]int, error) {
+	lines := make(chan string, 1000)
+	results := make(chan map[string]int, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wordCounts := make(map[string]int)
+			for line := range lines {
+				tokenize(line, func(word string) {
+					wordCounts[word]++
+				})
+			}
+			results <- wordCounts
+		}()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	go func() {
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	finalCounts := make(map[string]int)
+	for workerCounts := range results {
+		for word, count := range workerCounts {
+			finalCounts[word] += count
+		}
+	}
+	return finalCounts, scanner.Err()
+}
+
+func TestCountMatchesLineChannel(t *testing.T) {
+	data := testCorpus(5000)
+	want, err := countWithScanner(bytes.NewReader(data), 4, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Count(data, fields, Options{Workers: 4}).Merge(4)
+	if got.Len() != len(want) {
+		t.Fatalf("got %d distinct words, want %d", got.Len(), len(want))
+	}
+	for word, count := range want {
+		if n := got.Get(word); n != count {
+			t.Errorf("%s: got %d, want %d", word, n, count)
+		}
+	}
+}
+
+func TestChunkSizeFor(t *testing.T) {
+	tests := []struct {
+		size, workers, minChunks int
+	}{
+		{15 << 20, 12, 12},      // Small files still give every worker a chunk
+		{100 << 20, 12, 4 * 12}, // Four chunks per worker
+		{10 << 30, 12, 640},     // Large files keep the largest chunk size
+		{100, 12, 1},
+	}
+	for _, tt := range tests {
+		size := ChunkSizeFor(tt.size, tt.workers)
+		if size < MinChunkSize || size > ChunkSize {
+			t.Errorf("ChunkSizeFor(%d, %d) = %d, outside %d-%d", tt.size, tt.workers, size, MinChunkSize, ChunkSize)
+		}
+		if chunks := (tt.size + size - 1) / size; chunks < tt.minChunks {
+			t.Errorf("ChunkSizeFor(%d, %d) = %d gives %d chunks, want at least %d", tt.size, tt.workers, size, chunks, tt.minChunks)
+		}
+	}
+}
+
+func BenchmarkLineChannel(b *testing.B) {
+	data := benchCorpus(b)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		if _, err := countWithScanner(bytes.NewReader(data), benchWorkers, fields); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	data := benchCorpus(b)
+	b.SetBytes(int64(len(data)))
+	for i := 0; i < b.N; i++ {
+		Count(data, fields, Options{Workers: benchWorkers}).Merge(benchWorkers)
+	}
+}